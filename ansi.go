@@ -0,0 +1,395 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+)
+
+var (
+	_ Surface    = &ANSISurface{}
+	_ CellReader = &ANSISurface{}
+)
+
+// ANSIMode determines how an ANSISurface writes frames.
+type ANSIMode int
+
+// Available ANSI modes.
+const (
+	// ANSIFull draws each frame in place, like a full-screen UI. It moves
+	// the cursor with escape sequences, and only writes the cells that
+	// changed since the previous frame.
+	ANSIFull ANSIMode = iota
+	// ANSILines writes each frame as lines of text after the previous
+	// frame. Escape sequences are only used for colors and decorations,
+	// e.g. for CI logs.
+	ANSILines
+	// ANSIPlain writes each frame as lines of plain text, without any
+	// escape sequences, e.g. for files.
+	ANSIPlain
+	// ANSIInline draws each frame in place, like ANSIFull, but in the lines
+	// starting at the cursor instead of on the whole screen. The cursor is
+	// only moved relative to where it was, so the lines above are left
+	// alone. Call Detach once done to leave the last frame in the scrollback.
+	ANSIInline
+)
+
+// ansiCell is a cell painted on an ANSISurface.
+type ansiCell struct {
+	ch    rune
+	style Style
+	set   bool
+}
+
+// ANSISurface is a Surface that writes to an io.Writer using the ANSI escape
+// sequences understood by virtually all terminals. Unlike the surface used by
+// New, it doesn't need a terminfo database, or a terminal at all.
+//
+// To render a widget tree once, paint it with a Painter:
+//
+//	s := tui.NewANSISurface(os.Stdout, 40, 10, tui.ANSILines)
+//	tui.NewPainter(s, tui.DefaultTheme).Repaint(root)
+type ANSISurface struct {
+	w    io.Writer
+	mode ANSIMode
+	size image.Point
+
+	cells   []ansiCell
+	changed []bool
+	cleared bool
+
+	// row is the line the terminal cursor is on, relative to the top of the
+	// surface, in ANSIInline mode.
+	row int
+
+	cursor        image.Point
+	cursorVisible bool
+
+	// colors is the number of palette colors the terminal can show.
+	colors int
+
+	err error
+}
+
+// NewANSISurface returns a surface of the given size that writes to w.
+func NewANSISurface(w io.Writer, width, height int, mode ANSIMode) *ANSISurface {
+	s := &ANSISurface{
+		w:      w,
+		mode:   mode,
+		colors: 256,
+	}
+	s.SetSize(width, height)
+	return s
+}
+
+// SetSize changes the size of the surface. The contents are cleared.
+func (s *ANSISurface) SetSize(width, height int) {
+	s.size = image.Point{width, height}
+	s.cells = make([]ansiCell, width*height)
+	s.changed = make([]bool, width*height)
+	s.cleared = true
+}
+
+// SetColors sets how many colors of the palette the terminal can show, e.g. 8
+// or 16 for older terminals. Bright colors beyond those are shown as their
+// normal counterparts, and others in the default color. The default is 256.
+func (s *ANSISurface) SetColors(n int) {
+	s.colors = n
+}
+
+// Size returns the size of the surface.
+func (s *ANSISurface) Size() image.Point {
+	return s.size
+}
+
+// SetCell sets the contents of a cell. It's written with the next frame.
+func (s *ANSISurface) SetCell(x, y int, ch rune, style Style) {
+	if x < 0 || y < 0 || x >= s.size.X || y >= s.size.Y {
+		return
+	}
+	i := y*s.size.X + x
+	s.cells[i] = ansiCell{ch: ch, style: style, set: true}
+	s.changed[i] = true
+}
+
+// Cell returns the contents of a cell, or false if it hasn't been painted.
+func (s *ANSISurface) Cell(x, y int) (rune, Style, bool) {
+	if x < 0 || y < 0 || x >= s.size.X || y >= s.size.Y {
+		return 0, Style{}, false
+	}
+	c := s.cells[y*s.size.X+x]
+	return c.ch, c.style, c.set
+}
+
+// SetCursor shows the cursor at the given position.
+func (s *ANSISurface) SetCursor(x, y int) {
+	s.cursor = image.Point{x, y}
+	s.cursorVisible = true
+}
+
+// HideCursor hides the cursor.
+func (s *ANSISurface) HideCursor() {
+	s.cursorVisible = false
+}
+
+// Begin clears the surface.
+func (s *ANSISurface) Begin() {
+	for i := range s.cells {
+		s.cells[i] = ansiCell{}
+		s.changed[i] = false
+	}
+	s.cleared = true
+}
+
+// End writes the frame.
+func (s *ANSISurface) End() {
+	var buf bytes.Buffer
+
+	if s.mode == ANSIFull || s.mode == ANSIInline {
+		s.writeChanges(&buf)
+	} else {
+		s.writeLines(&buf)
+	}
+
+	for i := range s.changed {
+		s.changed[i] = false
+	}
+	s.cleared = false
+
+	s.write(buf.Bytes())
+}
+
+// Sync writes the whole frame again right away, e.g. after the terminal was
+// drawn over by another program.
+func (s *ANSISurface) Sync() {
+	for i := range s.changed {
+		s.changed[i] = s.cells[i].set
+	}
+	s.cleared = true
+	s.End()
+}
+
+// Detach leaves the last frame on the terminal, and shows the cursor. In
+// ANSIInline mode, the cursor is moved to the line below the frame, so that
+// whatever is written next doesn't overwrite it. The next frame is drawn in a
+// new area starting there, so Painter.Invalidate must be called before
+// painting again.
+func (s *ANSISurface) Detach() {
+	var buf bytes.Buffer
+
+	switch s.mode {
+	case ANSIFull:
+		buf.WriteString("\x1b[0m\x1b[?25h")
+	case ANSIInline:
+		buf.WriteString("\x1b[0m\r")
+		s.moveTo(&buf, image.Point{0, maxOf(s.size.Y-1, 0)})
+		buf.WriteString("\n\x1b[?25h")
+		s.row = 0
+		s.cleared = true
+	default:
+		return
+	}
+
+	s.write(buf.Bytes())
+}
+
+// write writes to the underlying writer, and remembers the first error.
+func (s *ANSISurface) write(b []byte) {
+	if _, err := s.w.Write(b); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// Err returns the first error that occurred while writing, if any.
+func (s *ANSISurface) Err() error {
+	return s.err
+}
+
+// writeChanges writes the cells that changed since the previous frame,
+// moving the cursor to each of them.
+func (s *ANSISurface) writeChanges(buf *bytes.Buffer) {
+	buf.WriteString("\x1b[?25l")
+	if s.cleared {
+		s.clear(buf)
+	}
+
+	var (
+		pen   = image.Point{-1, -1}
+		style *Style
+	)
+	for i, c := range s.cells {
+		if !s.changed[i] {
+			continue
+		}
+		pos := image.Point{i % s.size.X, i / s.size.X}
+		if pos != pen {
+			s.moveTo(buf, pos)
+		}
+		if !c.set {
+			c.ch = ' '
+		}
+		if style == nil || *style != c.style {
+			buf.WriteString(sgr(c.style, s.colors))
+			st := c.style
+			style = &st
+		}
+		buf.WriteRune(c.ch)
+		pen = pos.Add(image.Point{runeWidth(c.ch), 0})
+	}
+
+	buf.WriteString("\x1b[0m")
+	if s.cursorVisible {
+		s.moveTo(buf, s.cursor)
+		buf.WriteString("\x1b[?25h")
+	}
+}
+
+// clear clears the screen, or in ANSIInline mode, the lines of the surface.
+// Lines are added below the cursor first if there aren't enough of them.
+func (s *ANSISurface) clear(buf *bytes.Buffer) {
+	buf.WriteString("\x1b[0m")
+
+	if s.mode != ANSIInline {
+		buf.WriteString("\x1b[H\x1b[2J")
+		return
+	}
+
+	buf.WriteString("\r")
+	s.moveTo(buf, image.Point{})
+	if s.size.Y > 1 {
+		buf.WriteString(strings.Repeat("\n", s.size.Y-1))
+		fmt.Fprintf(buf, "\x1b[%dA", s.size.Y-1)
+	}
+	buf.WriteString("\x1b[J")
+}
+
+// moveTo moves the terminal cursor to a cell of the surface.
+func (s *ANSISurface) moveTo(buf *bytes.Buffer, pos image.Point) {
+	if s.mode != ANSIInline {
+		fmt.Fprintf(buf, "\x1b[%d;%dH", pos.Y+1, pos.X+1)
+		return
+	}
+
+	switch dy := pos.Y - s.row; {
+	case dy < 0:
+		fmt.Fprintf(buf, "\x1b[%dA", -dy)
+	case dy > 0:
+		fmt.Fprintf(buf, "\x1b[%dB", dy)
+	}
+	fmt.Fprintf(buf, "\x1b[%dG", pos.X+1)
+	s.row = pos.Y
+}
+
+// writeLines writes every line of the frame. Trailing blank cells are left
+// out.
+func (s *ANSISurface) writeLines(buf *bytes.Buffer) {
+	for y := 0; y < s.size.Y; y++ {
+		row := s.cells[y*s.size.X : (y+1)*s.size.X]
+
+		end := len(row)
+		for end > 0 && (!row[end-1].set || row[end-1].ch == ' ' && row[end-1].style == Style{}) {
+			end--
+		}
+
+		var style Style
+		for x := 0; x < end; x++ {
+			c := row[x]
+			if !c.set {
+				c = ansiCell{ch: ' '}
+			}
+			if s.mode == ANSILines && c.style != style {
+				buf.WriteString(sgr(c.style, s.colors))
+				style = c.style
+			}
+			buf.WriteRune(c.ch)
+			// Wide runes cover the next cell.
+			x += runeWidth(c.ch) - 1
+		}
+		if style != (Style{}) {
+			buf.WriteString("\x1b[0m")
+		}
+		buf.WriteString("\n")
+	}
+}
+
+// sgr returns the escape sequence that sets the colors and decorations of a
+// style, on a terminal that shows the given number of palette colors.
+func sgr(s Style, colors int) string {
+	codes := []string{"0"}
+	if s.Bold == DecorationOn {
+		codes = append(codes, "1")
+	}
+	if s.Dim == DecorationOn {
+		codes = append(codes, "2")
+	}
+	if s.Underline == DecorationOn {
+		codes = append(codes, "4")
+	}
+	if s.Reverse == DecorationOn {
+		codes = append(codes, "7")
+	}
+	if i, ok := limitColor(s.Fg, colors); ok {
+		codes = append(codes, colorCode(i, 30, 90, 38))
+	}
+	if i, ok := limitColor(s.Bg, colors); ok {
+		codes = append(codes, colorCode(i, 40, 100, 48))
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// limitColor returns the palette index of a color, like ansiColor, for a
+// terminal that only shows the first n colors. Bright colors beyond those are
+// replaced by their normal counterparts, and others by the default color.
+func limitColor(c Color, n int) (int, bool) {
+	i, ok := ansiColor(c)
+	switch {
+	case !ok || i < n:
+		return i, ok
+	case i < 16 && i-8 < n:
+		return i - 8, true
+	}
+	return 0, false
+}
+
+// colorCode returns the parameters that select a palette color, using the
+// shortest form that the color allows.
+func colorCode(i, base, bright, extended int) string {
+	switch {
+	case i < 8:
+		return fmt.Sprint(base + i)
+	case i < 16:
+		return fmt.Sprint(bright + i - 8)
+	}
+	return fmt.Sprintf("%d;5;%d", extended, i)
+}
+
+// ansiColor returns the index of a color in the 256-color palette, or false
+// for the default color. Colors beyond the named ones are palette indexes.
+func ansiColor(c Color) (int, bool) {
+	switch c {
+	case ColorDefault:
+		return 0, false
+	case ColorBlack:
+		return 0, true
+	case ColorWhite:
+		return 15, true
+	case ColorRed:
+		return 9, true
+	case ColorGreen:
+		return 2, true
+	case ColorBlue:
+		return 12, true
+	case ColorCyan:
+		return 6, true
+	case ColorMagenta:
+		return 5, true
+	case ColorYellow:
+		return 11, true
+	}
+	if c > 0 && c < 256 {
+		return int(c), true
+	}
+	return 0, false
+}
@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestANSISurface_Full(t *testing.T) {
+	var buf bytes.Buffer
+
+	label := NewLabel("ab")
+	label.SetStyleName("warning")
+
+	theme := NewTheme()
+	theme.SetStyle("label.warning", Style{Fg: ColorRed, Bold: DecorationOn})
+
+	s := NewANSISurface(&buf, 4, 2, ANSIFull)
+	p := NewPainter(s, theme)
+	p.Repaint(NewVBox(label, NewLabel("c")))
+
+	want := "\x1b[?25l\x1b[0m\x1b[H\x1b[2J" +
+		"\x1b[1;1H\x1b[0;1;91mab\x1b[0m  " +
+		"\x1b[2;1Hc   " +
+		"\x1b[0m"
+	if got := buf.String(); got != want {
+		t.Errorf("got = %q; want = %q", got, want)
+	}
+
+	// Only the cells that changed are written in the next frame.
+	buf.Reset()
+	label.SetText("ax")
+	p.Repaint(NewVBox(label, NewLabel("c")))
+
+	want = "\x1b[?25l\x1b[1;2H\x1b[0;1;91mx\x1b[0m"
+	if got := buf.String(); got != want {
+		t.Errorf("got = %q; want = %q", got, want)
+	}
+
+	// The cursor is shown where the entry draws it.
+	buf.Reset()
+	entry := NewEntry()
+	entry.SetText("hi")
+	entry.SetFocused(true)
+	p.Repaint(entry)
+
+	want = "\x1b[?25l\x1b[1;1H\x1b[0mhi\x1b[2;1H    \x1b[0m\x1b[1;3H\x1b[?25h"
+	if got := buf.String(); got != want {
+		t.Errorf("got = %q; want = %q", got, want)
+	}
+}
+
+func TestANSISurface_Lines(t *testing.T) {
+	for _, tt := range []struct {
+		mode ANSIMode
+		want string
+	}{
+		{ANSILines, "\x1b[0;7m世界\x1b[0m b\n\n  \x1b[0;4mu\x1b[0m\n"},
+		{ANSIPlain, "世界 b\n\n  u\n"},
+	} {
+		var buf bytes.Buffer
+
+		s := NewANSISurface(&buf, 8, 3, tt.mode)
+		s.SetCell(0, 0, '世', Style{Reverse: DecorationOn})
+		s.SetCell(2, 0, '界', Style{Reverse: DecorationOn})
+		s.SetCell(5, 0, 'b', Style{})
+		s.SetCell(2, 2, 'u', Style{Underline: DecorationOn})
+		s.SetCell(3, 2, ' ', Style{})
+		s.End()
+
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%d: got = %q; want = %q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestSGR(t *testing.T) {
+	for _, tt := range []struct {
+		style  Style
+		colors int
+		want   string
+	}{
+		{Style{}, 256, "\x1b[0m"},
+		{Style{Fg: ColorBlack, Bg: ColorWhite}, 256, "\x1b[0;30;107m"},
+		{Style{Fg: ColorCyan, Bg: ColorBlue}, 256, "\x1b[0;36;104m"},
+		{Style{Fg: Color(196), Bg: Color(100)}, 256, "\x1b[0;38;5;196;48;5;100m"},
+		{Style{Bold: DecorationOn, Dim: DecorationOn, Underline: DecorationOn, Reverse: DecorationOn}, 256, "\x1b[0;1;2;4;7m"},
+		{Style{Bold: DecorationOff}, 256, "\x1b[0m"},
+		// Colors the terminal can't show are replaced.
+		{Style{Fg: ColorWhite, Bg: Color(196)}, 16, "\x1b[0;97m"},
+		{Style{Fg: ColorWhite, Bg: ColorBlue}, 8, "\x1b[0;37;44m"},
+		{Style{Fg: ColorRed, Reverse: DecorationOn}, 0, "\x1b[0;7m"},
+	} {
+		if got := sgr(tt.style, tt.colors); got != tt.want {
+			t.Errorf("%+v with %d colors: got = %q; want = %q", tt.style, tt.colors, got, tt.want)
+		}
+	}
+}
+
+func TestANSISurface_Inline(t *testing.T) {
+	var buf bytes.Buffer
+
+	label := NewLabel("ab")
+	root := NewVBox(label, NewLabel("c"))
+
+	s := NewANSISurface(&buf, 3, 2, ANSIInline)
+	p := NewPainter(s, NewTheme())
+	p.Repaint(root)
+
+	// The lines are made room for, and the cursor is only moved relative
+	// to where it was.
+	want := "\x1b[?25l\x1b[0m\r\x1b[1G\n\x1b[1A\x1b[J" +
+		"\x1b[1G\x1b[0mab \x1b[1B\x1b[1Gc  " +
+		"\x1b[0m"
+	if got := buf.String(); got != want {
+		t.Errorf("got = %q; want = %q", got, want)
+	}
+
+	buf.Reset()
+	label.SetText("ax")
+	p.Repaint(root)
+
+	want = "\x1b[?25l\x1b[1A\x1b[2G\x1b[0mx\x1b[0m"
+	if got := buf.String(); got != want {
+		t.Errorf("got = %q; want = %q", got, want)
+	}
+
+	// Detaching moves the cursor below the frame.
+	buf.Reset()
+	s.Detach()
+
+	want = "\x1b[0m\r\x1b[1B\x1b[1G\n\x1b[?25h"
+	if got := buf.String(); got != want {
+		t.Errorf("got = %q; want = %q", got, want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 )
 
 var _ Widget = &Box{}
+var _ Container = &Box{}
 
 // Alignment is used to set the direction in which widgets are laid out.
 type Alignment int
@@ -31,32 +32,39 @@ type Box struct {
 
 // NewVBox returns a new vertically aligned Box.
 func NewVBox(c ...Widget) *Box {
-	return &Box{
+	b := &Box{
 		children:  c,
 		alignment: Vertical,
 	}
+	b.trackDirtyFor(b)
+	return b
 }
 
 // NewHBox returns a new horizontally aligned Box.
 func NewHBox(c ...Widget) *Box {
-	return &Box{
+	b := &Box{
 		children:  c,
 		alignment: Horizontal,
 	}
+	b.trackDirtyFor(b)
+	return b
 }
 
 // Append adds the given widget at the end of the Box.
 func (b *Box) Append(w Widget) {
+	b.MarkDirty()
 	b.children = append(b.children, w)
 }
 
 // Prepend adds the given widget at the start of the Box.
 func (b *Box) Prepend(w Widget) {
+	b.MarkDirty()
 	b.children = append([]Widget{w}, b.children...)
 }
 
 // Insert adds the widget into the Box at a given index.
 func (b *Box) Insert(i int, w Widget) {
+	b.MarkDirty()
 	if len(b.children) < i || i < 0 {
 		return
 	}
@@ -68,6 +76,7 @@ func (b *Box) Insert(i int, w Widget) {
 
 // Remove deletes the widget from the Box at a given index.
 func (b *Box) Remove(i int) {
+	b.MarkDirty()
 	if len(b.children) <= i || i < 0 {
 		return
 	}
@@ -82,11 +91,13 @@ func (b *Box) Length() int {
 
 // SetBorder sets whether the border is visible or not.
 func (b *Box) SetBorder(enabled bool) {
+	b.MarkDirty()
 	b.border = enabled
 }
 
 // SetTitle sets the title of the box.
 func (b *Box) SetTitle(title string) {
+	b.MarkDirty()
 	b.title = title
 }
 
@@ -95,6 +106,31 @@ func (b *Box) Alignment() Alignment {
 	return b.alignment
 }
 
+// Children returns the widgets in the Box.
+func (b *Box) Children() []Widget {
+	return b.children
+}
+
+// ChildBounds returns the area occupied by the given child widget.
+func (b *Box) ChildBounds(w Widget) image.Rectangle {
+	var off image.Point
+	if b.border {
+		off = image.Point{1, 1}
+	}
+	for _, child := range b.children {
+		if child == w {
+			return image.Rectangle{Min: off, Max: off.Add(child.Size())}
+		}
+		switch b.Alignment() {
+		case Horizontal:
+			off.X += child.Size().X
+		case Vertical:
+			off.Y += child.Size().Y
+		}
+	}
+	return image.Rectangle{}
+}
+
 // IsFocused return true if one of the children is focused.
 func (b *Box) IsFocused() bool {
 	for _, w := range b.children {
@@ -145,7 +181,7 @@ func (b *Box) Draw(p *Painter) {
 				Min: image.Point{},
 				Max: child.Size(),
 			}, func(p *Painter) {
-				child.Draw(p)
+				p.DrawWidget(child)
 			})
 
 			p.Restore()
@@ -207,13 +243,6 @@ func (b *Box) SizeHint() image.Point {
 	return sizeHint
 }
 
-// OnKeyEvent handles an event and propagates it to all children.
-func (b *Box) OnKeyEvent(ev KeyEvent) {
-	for _, child := range b.children {
-		child.OnKeyEvent(ev)
-	}
-}
-
 // Resize recursively updates the size of the Box and all the widgets it
 // contains. This is a potentially expensive operation and should be invoked
 // with restraint.
@@ -221,7 +250,7 @@ func (b *Box) OnKeyEvent(ev KeyEvent) {
 // Resize is called by the layout engine and is not intended to be used by end
 // users.
 func (b *Box) Resize(size image.Point) {
-	b.size = size
+	b.WidgetBase.Resize(size)
 	inner := b.size
 	if b.border {
 		inner = b.size.Sub(image.Point{2, 2})
@@ -0,0 +1,57 @@
+package tui
+
+import "image"
+
+// bufferCell is a cell in a cellBuffer. Cells that haven't been painted are
+// left unset.
+type bufferCell struct {
+	Rune  rune
+	Style Style
+	set   bool
+}
+
+// cellBuffer holds the contents of a frame.
+type cellBuffer struct {
+	size  image.Point
+	cells []bufferCell
+}
+
+func newCellBuffer(size image.Point) *cellBuffer {
+	return &cellBuffer{
+		size:  size,
+		cells: make([]bufferCell, size.X*size.Y),
+	}
+}
+
+// reset unsets all cells in the buffer.
+func (b *cellBuffer) reset() {
+	for i := range b.cells {
+		b.cells[i] = bufferCell{}
+	}
+}
+
+func (b *cellBuffer) inside(x, y int) bool {
+	return x >= 0 && y >= 0 && x < b.size.X && y < b.size.Y
+}
+
+func (b *cellBuffer) setCell(x, y int, ch rune, s Style) {
+	if b.inside(x, y) {
+		b.cells[y*b.size.X+x] = bufferCell{Rune: ch, Style: s, set: true}
+	}
+}
+
+// flushTo paints every cell that differs from the corresponding cell in prev
+// on the surface. Cells that have been unset since prev are painted blank.
+func (b *cellBuffer) flushTo(s Surface, prev *cellBuffer) {
+	for i, c := range b.cells {
+		if c == prev.cells[i] {
+			continue
+		}
+		x, y := i%b.size.X, i/b.size.X
+		if c.set {
+			s.SetCell(x, y, c.Rune, c.Style)
+		} else {
+			s.SetCell(x, y, ' ', Style{})
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 )
 
 var _ Widget = &Button{}
+var _ Focusable = &Button{}
+var _ MouseHandler = &Button{}
 
 // Button is a widget that can be activated to perform some action, or to
 // answer a question.
@@ -19,9 +21,11 @@ type Button struct {
 
 // NewButton returns a new Button with the given text as the label.
 func NewButton(text string) *Button {
-	return &Button{
+	b := &Button{
 		text: text,
 	}
+	b.trackDirtyFor(b)
+	return b
 }
 
 // Draw draws the button.
@@ -57,6 +61,11 @@ func (b *Button) SizeHint() image.Point {
 	return size
 }
 
+// Focusable returns whether the button can be focused.
+func (b *Button) Focusable() bool {
+	return b.IsEnabled()
+}
+
 // OnKeyEvent handles keys events.
 func (b *Button) OnKeyEvent(ev KeyEvent) {
 	if !b.IsFocused() {
@@ -64,6 +73,24 @@ func (b *Button) OnKeyEvent(ev KeyEvent) {
 	}
 	if ev.Key == KeyEnter && b.onActivated != nil {
 		b.onActivated(b)
+		ev.Consume()
+	}
+}
+
+// OnMouseEvent activates the button when it is clicked with the left mouse
+// button.
+func (b *Button) OnMouseEvent(ev MouseEvent) {
+	if !b.IsEnabled() {
+		return
+	}
+	if ev.Action != MouseRelease || ev.Button != MouseButtonLeft {
+		return
+	}
+	if !ev.Pos.In(image.Rectangle{Max: b.Size()}) {
+		return
+	}
+	if b.onActivated != nil {
+		b.onActivated(b)
 	}
 }
 
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"image"
 	"testing"
 )
 
@@ -53,3 +54,33 @@ test
 		t.Error(diff)
 	}
 }
+
+func TestButton_OnMouseEvent(t *testing.T) {
+	btn := NewButton("test")
+	btn.Resize(image.Point{4, 1})
+
+	var invoked int
+	btn.OnActivated(func(b *Button) {
+		invoked++
+	})
+
+	btn.OnMouseEvent(MouseEvent{Pos: image.Pt(1, 0), Action: MousePress, Button: MouseButtonLeft})
+	if invoked != 0 {
+		t.Errorf("button should not be activated on press")
+	}
+
+	btn.OnMouseEvent(MouseEvent{Pos: image.Pt(1, 0), Action: MouseRelease, Button: MouseButtonLeft})
+	if invoked != 1 {
+		t.Errorf("button should be activated on release")
+	}
+
+	btn.OnMouseEvent(MouseEvent{Pos: image.Pt(5, 0), Action: MouseRelease, Button: MouseButtonLeft})
+	if invoked != 1 {
+		t.Errorf("button should not be activated when released outside")
+	}
+
+	btn.OnMouseEvent(MouseEvent{Pos: image.Pt(1, 0), Action: MouseRelease, Button: MouseButtonRight})
+	if invoked != 1 {
+		t.Errorf("button should not be activated by right button")
+	}
+}
@@ -6,6 +6,7 @@ import (
 )
 
 var _ Widget = &Entry{}
+var _ Focusable = &Entry{}
 
 // EchoMode is used to determine the visibility of Entry text.
 type EchoMode int
@@ -38,7 +39,9 @@ type Entry struct {
 
 // NewEntry returns a new Entry.
 func NewEntry() *Entry {
-	return &Entry{}
+	e := &Entry{}
+	e.trackDirtyFor(e)
+	return e
 }
 
 // Draw draws the entry.
@@ -77,21 +80,29 @@ func (e *Entry) SizeHint() image.Point {
 	return image.Point{10, 1}
 }
 
+// Focusable returns whether the entry can be focused.
+func (e *Entry) Focusable() bool {
+	return e.IsEnabled()
+}
+
 // OnKeyEvent handles key events.
 func (e *Entry) OnKeyEvent(ev KeyEvent) {
 	if !e.IsFocused() {
 		return
 	}
 
+	e.MarkDirty()
+
 	screenWidth := e.Size().X
 	e.text.SetMaxWidth(screenWidth)
 
 	if ev.Key != KeyRune {
 		switch ev.Key {
 		case KeyEnter:
-			if e.onSubmit != nil {
-				e.onSubmit(e)
+			if e.onSubmit == nil {
+				return
 			}
+			e.onSubmit(e)
 		case KeyBackspace:
 			fallthrough
 		case KeyBackspace2:
@@ -129,9 +140,18 @@ func (e *Entry) OnKeyEvent(ev KeyEvent) {
 			e.ensureCursorIsVisible()
 		case KeyCtrlK:
 			e.text.Kill()
+		default:
+			return
 		}
+		ev.Consume()
+		return
+	}
+
+	// Leave shortcuts like Alt+X to the keybindings.
+	if ev.Modifiers&(ModAlt|ModMeta) != 0 {
 		return
 	}
+	ev.Consume()
 
 	e.text.WriteRune(ev.Rune)
 	if e.text.CursorPos().X >= screenWidth {
@@ -142,6 +162,29 @@ func (e *Entry) OnKeyEvent(ev KeyEvent) {
 	}
 }
 
+// OnPasteEvent inserts pasted text at the cursor. Line breaks are replaced
+// by spaces, since the entry holds a single line of text.
+func (e *Entry) OnPasteEvent(ev PasteEvent) {
+	if !e.IsFocused() {
+		return
+	}
+
+	e.MarkDirty()
+
+	screenWidth := e.Size().X
+	e.text.SetMaxWidth(screenWidth)
+
+	text := strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(ev.Text)
+	e.text.WriteRunes([]rune(text))
+
+	if x := e.text.CursorPos().X; x-e.offset >= screenWidth {
+		e.offset = x - screenWidth + 1
+	}
+	if e.onTextChange != nil {
+		e.onTextChange(e)
+	}
+}
+
 // OnChanged sets a function to be run whenever the content of the entry has
 // been changed.
 func (e *Entry) OnChanged(fn func(entry *Entry)) {
@@ -156,11 +199,13 @@ func (e *Entry) OnSubmit(fn func(entry *Entry)) {
 
 // SetEchoMode sets the echo mode of the entry.
 func (e *Entry) SetEchoMode(m EchoMode) {
+	e.MarkDirty()
 	e.echoMode = m
 }
 
 // SetText sets the text content of the entry.
 func (e *Entry) SetText(text string) {
+	e.MarkDirty()
 	e.text.Set([]rune(text))
 	// TODO: Enable when RuneBuf supports cursor movement for CJK.
 	// e.ensureCursorIsVisible()
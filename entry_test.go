@@ -632,3 +632,31 @@ func repeatKeyEvent(e *Entry, ev KeyEvent, n int) {
 		e.OnKeyEvent(ev)
 	}
 }
+
+func TestEntry_OnKeyEvent_Consume(t *testing.T) {
+	for _, tt := range []struct {
+		event    KeyEvent
+		consumed bool
+	}{
+		{KeyEvent{Key: KeyRune, Rune: 'q'}, true},
+		{KeyEvent{Key: KeyBackspace2}, true},
+		{KeyEvent{Key: KeyLeft}, true},
+		{KeyEvent{Key: KeyEsc}, false},
+		{KeyEvent{Key: KeyEnter}, false},
+		{KeyEvent{Key: KeyRune, Rune: 'x', Modifiers: ModAlt}, false},
+	} {
+		tt := tt
+		t.Run(tt.event.Name(), func(t *testing.T) {
+			e := NewEntry()
+			e.SetFocused(true)
+
+			ev := tt.event
+			ev.state = &eventState{}
+			e.OnKeyEvent(ev)
+
+			if ev.Consumed() != tt.consumed {
+				t.Errorf("got = %v; want = %v", ev.Consumed(), tt.consumed)
+			}
+		})
+	}
+}
@@ -19,10 +19,34 @@ const (
 )
 
 // KeyEvent represents a key press.
+//
+// The UI first sends a key event to the focused widget, then to each of the
+// containers it's in, and finally to the keybindings. Any of them can call
+// Consume to stop the event from propagating any further.
 type KeyEvent struct {
 	Key       Key
 	Rune      rune
 	Modifiers ModMask
+
+	// state is shared by all copies of the event passed to the handlers.
+	state *eventState
+}
+
+type eventState struct {
+	consumed bool
+}
+
+// Consume marks the event as handled, which stops it from being sent to any
+// other widget or keybinding.
+func (ev KeyEvent) Consume() {
+	if ev.state != nil {
+		ev.state.consumed = true
+	}
+}
+
+// Consumed returns whether the event has been handled.
+func (ev KeyEvent) Consumed() bool {
+	return ev.state != nil && ev.state.consumed
 }
 
 // Name returns a user-friendly description of the key press.
@@ -359,14 +383,65 @@ var keyNames = map[Key]string{
 	KeyCtrlZ:          "Ctrl-Z",
 }
 
+// MouseButton identifies a mouse button.
+type MouseButton int
+
+// Mouse buttons that can be sent with a MouseEvent.
+const (
+	MouseButtonNone MouseButton = iota
+	MouseButtonLeft
+	MouseButtonMiddle
+	MouseButtonRight
+)
+
+// MouseAction describes what happened to the mouse.
+type MouseAction int
+
+// Actions that can be sent with a MouseEvent.
+const (
+	// MouseMove is sent when the pointer moves with no button held down.
+	MouseMove MouseAction = iota
+	// MousePress is sent when a button is pressed.
+	MousePress
+	// MouseRelease is sent when a button is released.
+	MouseRelease
+	// MouseDrag is sent when the pointer moves while a button is held down.
+	MouseDrag
+	// MouseWheel is sent when the wheel is scrolled.
+	MouseWheel
+)
+
+// WheelDirection is the direction the mouse wheel was scrolled in.
+type WheelDirection int
+
+// Wheel directions that can be sent with a MouseEvent.
+const (
+	WheelNone WheelDirection = iota
+	WheelUp
+	WheelDown
+	WheelLeft
+	WheelRight
+)
+
 // MouseEvent represents the event where a mouse button was pressed or
-// released.
+// released, the pointer moved or the wheel was scrolled.
+//
+// Pos is relative to the top-left corner of the widget receiving the event.
 type MouseEvent struct {
-	Pos image.Point
+	Pos       image.Point
+	Button    MouseButton
+	Action    MouseAction
+	Wheel     WheelDirection
+	Modifiers ModMask
 }
 
 type paintEvent struct{}
 
+// PasteEvent holds text pasted into the terminal.
+type PasteEvent struct {
+	Text string
+}
+
 // callbackEvent holds a user-defined function which has been submitted
 // to be called on the render thread.
 type callbackEvent struct {
@@ -62,13 +62,11 @@ func main() {
 	}
 
 	library.OnItemActivated(func(t *tui.Table) {
-		p.play(songs[t.Selected()-1], func(curr, max int) {
-			ui.Update(func() {
-				progress.SetCurrent(curr)
-				progress.SetMax(max)
+		p.play(ui, songs[t.Selected()-1], func(curr, max int) {
+			progress.SetCurrent(curr)
+			progress.SetMax(max)
 
-				status.SetText(fmt.Sprintf("%s / %s", time.Duration(curr)*time.Second, time.Duration(max)*time.Second))
-			})
+			status.SetText(fmt.Sprintf("%s / %s", time.Duration(curr)*time.Second, time.Duration(max)*time.Second))
 		})
 	})
 
@@ -83,33 +81,23 @@ func main() {
 type player struct {
 	elapsed int
 	total   int
-	quit    chan struct{}
+	ticker  *tui.Timer
 }
 
-func (p *player) play(s song, callback func(current, max int)) {
-	if p.quit != nil {
-		p.quit <- struct{}{}
-		<-p.quit
+func (p *player) play(ui tui.UI, s song, callback func(current, max int)) {
+	if p.ticker != nil {
+		p.ticker.Stop()
 	}
 
-	p.quit = make(chan struct{})
 	p.total = int(s.duration.Seconds())
 	p.elapsed = 0
 
-	go func() {
-		for {
-			select {
-			case <-time.After(1 * time.Second):
-				if p.elapsed >= p.total {
-					p.quit <- struct{}{}
-				}
-
-				callback(p.elapsed, p.total)
-				p.elapsed++
-			case <-p.quit:
-				p.quit <- struct{}{}
-				return
-			}
+	p.ticker = ui.Every(1*time.Second, func() {
+		if p.elapsed >= p.total {
+			p.ticker.Stop()
 		}
-	}()
+
+		callback(p.elapsed, p.total)
+		p.elapsed++
+	})
 }
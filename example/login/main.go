@@ -14,7 +14,6 @@ var logo = `     _____ __ ____  ___   ______________
 
 func main() {
 	user := tui.NewEntry()
-	user.SetFocused(true)
 
 	password := tui.NewEntry()
 	password.SetEchoMode(tui.EchoModePassword)
@@ -58,13 +57,13 @@ func main() {
 		status,
 	)
 
-	tui.DefaultFocusChain.Set(user, password, login, register)
-
 	ui, err := tui.New(root)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	ui.SetFocusChain(tui.NewTreeFocusChain(root))
+
 	ui.SetKeybinding("Esc", func() { ui.Quit() })
 
 	if err := ui.Run(); err != nil {
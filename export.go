@@ -0,0 +1,251 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The colors used for the default foreground and background colors when
+// exporting a surface.
+const (
+	exportFg = "#e5e5e5"
+	exportBg = "#000000"
+)
+
+// The size of a cell in an SVG image, in pixels.
+const (
+	svgFontSize   = 14
+	svgCellWidth  = 8.4
+	svgCellHeight = 17
+)
+
+// CellReader is implemented by surfaces whose contents can be read back, such
+// as TestSurface and ANSISurface. It's all WriteHTML and WriteSVG need.
+type CellReader interface {
+	// Size returns the size of the surface, in cells.
+	Size() image.Point
+	// Cell returns the contents of a cell, or false if it hasn't been
+	// painted.
+	Cell(x, y int) (rune, Style, bool)
+}
+
+// exportRun is a run of cells on a line, painted in the same style. A wide
+// rune is a run of its own, covering more than one cell.
+type exportRun struct {
+	x, width int
+	text     string
+	style    Style
+}
+
+// WriteHTML writes the contents of the surface as a standalone HTML page. See
+// the WriteHTML function.
+func (s *TestSurface) WriteHTML(w io.Writer) error {
+	return WriteHTML(w, s)
+}
+
+// WriteSVG writes the contents of the surface as a standalone SVG image. See
+// the WriteSVG function.
+func (s *TestSurface) WriteSVG(w io.Writer) error {
+	return WriteSVG(w, s)
+}
+
+// WriteHTML writes the contents of a surface as a standalone HTML page, e.g.
+// to include a screenshot of a snapshot in the documentation. The text is
+// written in spans, with the colors and decorations of each cell as inline
+// styles. Colors are shown as in xterm's default palette.
+func WriteHTML(w io.Writer, s CellReader) error {
+	var buf bytes.Buffer
+	size := s.Size()
+
+	buf.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n</head>\n<body>\n")
+	fmt.Fprintf(&buf, "<pre style=\"display:inline-block;margin:0;padding:0.5em;font-family:monospace;line-height:1.2;color:%s;background:%s\">", exportFg, exportBg)
+
+	for y := 0; y < size.Y; y++ {
+		for _, r := range exportRuns(s, y) {
+			css := exportCSS(r.style)
+			if runeWidth([]rune(r.text)[0]) > 1 {
+				// Keep wide runes exactly as wide as the cells they
+				// cover, whatever the font.
+				css = append(css, fmt.Sprintf("display:inline-block;width:%dch", r.width))
+			}
+			text := html.EscapeString(r.text)
+			if len(css) == 0 {
+				buf.WriteString(text)
+				continue
+			}
+			fmt.Fprintf(&buf, "<span style=\"%s\">%s</span>", strings.Join(css, ";"), text)
+		}
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("</pre>\n</body>\n</html>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// WriteSVG writes the contents of a surface as a standalone SVG image, with
+// the same colors as WriteHTML. Each run of text is stretched to the width of
+// the cells it covers, so that the image looks the same whatever the font.
+func WriteSVG(w io.Writer, s CellReader) error {
+	var buf bytes.Buffer
+	size := s.Size()
+
+	width := float64(size.X) * svgCellWidth
+	height := size.Y * svgCellHeight
+
+	fmt.Fprintf(&buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%d\" viewBox=\"0 0 %s %d\" font-family=\"monospace\" font-size=\"%d\">\n", px(width), height, px(width), height, svgFontSize)
+	fmt.Fprintf(&buf, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", exportBg)
+
+	for y := 0; y < size.Y; y++ {
+		runs := exportRuns(s, y)
+
+		// Backgrounds go first, so that they don't cover the text of the
+		// cells before them.
+		for _, r := range runs {
+			if _, bg := exportColors(r.style); bg != exportBg {
+				fmt.Fprintf(&buf, "<rect x=\"%s\" y=\"%d\" width=\"%s\" height=\"%d\" fill=\"%s\"/>\n",
+					px(float64(r.x)*svgCellWidth), y*svgCellHeight, px(float64(r.width)*svgCellWidth), svgCellHeight, bg)
+			}
+		}
+
+		for _, r := range runs {
+			if strings.TrimSpace(r.text) == "" && r.style.Underline != DecorationOn {
+				continue
+			}
+
+			fg, _ := exportColors(r.style)
+			attrs := fmt.Sprintf(" fill=\"%s\"", fg)
+			if r.style.Bold == DecorationOn {
+				attrs += " font-weight=\"bold\""
+			}
+			if r.style.Underline == DecorationOn {
+				attrs += " text-decoration=\"underline\""
+			}
+			if r.style.Dim == DecorationOn {
+				attrs += " opacity=\"0.5\""
+			}
+
+			// The baseline is a fifth of the cell above its bottom.
+			fmt.Fprintf(&buf, "<text x=\"%s\" y=\"%s\" textLength=\"%s\" lengthAdjust=\"spacingAndGlyphs\" xml:space=\"preserve\"%s>%s</text>\n",
+				px(float64(r.x)*svgCellWidth), px(float64(y+1)*svgCellHeight-svgCellHeight/5.0), px(float64(r.width)*svgCellWidth), attrs, html.EscapeString(r.text))
+		}
+	}
+
+	buf.WriteString("</svg>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// px formats a length in pixels, rounded to hundredths.
+func px(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// exportRuns returns the runs of cells on a line. Unpainted cells are spaces
+// in the default style.
+func exportRuns(s CellReader, y int) []exportRun {
+	var runs []exportRun
+	width := s.Size().X
+	for x := 0; x < width; x++ {
+		ch, style, ok := s.Cell(x, y)
+		if !ok {
+			ch, style = ' ', Style{}
+		}
+
+		w := runeWidth(ch)
+		if w > 1 {
+			runs = append(runs, exportRun{x: x, width: minOf(w, width-x), text: string(ch), style: style})
+			x += w - 1
+			continue
+		}
+		if w < 1 {
+			// Control characters would break the output.
+			ch = ' '
+		}
+
+		if n := len(runs); n > 0 {
+			last := &runs[n-1]
+			if last.style == style && last.x+last.width == x && len([]rune(last.text)) == last.width {
+				last.text += string(ch)
+				last.width++
+				continue
+			}
+		}
+		runs = append(runs, exportRun{x: x, width: 1, text: string(ch), style: style})
+	}
+	return runs
+}
+
+// exportCSS returns the CSS properties for the colors and decorations of a
+// style that differ from the defaults.
+func exportCSS(st Style) []string {
+	var css []string
+	fg, bg := exportColors(st)
+	if fg != exportFg {
+		css = append(css, "color:"+fg)
+	}
+	if bg != exportBg {
+		css = append(css, "background:"+bg)
+	}
+	if st.Bold == DecorationOn {
+		css = append(css, "font-weight:bold")
+	}
+	if st.Underline == DecorationOn {
+		css = append(css, "text-decoration:underline")
+	}
+	if st.Dim == DecorationOn {
+		css = append(css, "opacity:0.5")
+	}
+	return css
+}
+
+// exportColors returns the foreground and background colors of a style, as
+// hex colors. Reverse swaps them.
+func exportColors(st Style) (fg, bg string) {
+	fg, bg = exportFg, exportBg
+	if i, ok := ansiColor(st.Fg); ok {
+		fg = paletteHex(i)
+	}
+	if i, ok := ansiColor(st.Bg); ok {
+		bg = paletteHex(i)
+	}
+	if st.Reverse == DecorationOn {
+		fg, bg = bg, fg
+	}
+	return fg, bg
+}
+
+// basePalette holds the first 16 colors of xterm's default palette.
+var basePalette = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// paletteHex returns a color of the 256-color palette as a hex color. The
+// colors after the first 16 are a 6x6x6 color cube, followed by 24 shades of
+// gray.
+func paletteHex(i int) string {
+	switch {
+	case i < 16:
+		return basePalette[i]
+	case i < 232:
+		i -= 16
+		level := func(n int) int {
+			if n == 0 {
+				return 0
+			}
+			return 55 + n*40
+		}
+		return fmt.Sprintf("#%02x%02x%02x", level(i/36), level(i/6%6), level(i%6))
+	}
+	gray := 8 + (i-232)*10
+	return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// exportSurface returns a surface with a few styles and wide runes.
+func exportSurface() *TestSurface {
+	s := NewTestSurface(8, 2)
+	s.SetCell(0, 0, '<', Style{Fg: ColorRed, Bold: DecorationOn})
+	s.SetCell(1, 0, 'a', Style{Fg: ColorRed, Bold: DecorationOn})
+	s.SetCell(2, 0, '世', Style{Reverse: DecorationOn})
+	s.SetCell(4, 0, 'b', Style{Bg: Color(202), Underline: DecorationOn})
+	s.SetCell(5, 0, 'c', Style{Dim: DecorationOn})
+	s.SetCell(0, 1, 'd', Style{})
+	return s
+}
+
+func TestTestSurface_WriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := exportSurface().WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<span style="color:#ff0000;font-weight:bold">&lt;a</span>` +
+		`<span style="color:#000000;background:#e5e5e5;display:inline-block;width:2ch">世</span>` +
+		`<span style="background:#ff5f00;text-decoration:underline">b</span>` +
+		`<span style="opacity:0.5">c</span>  ` + "\n" +
+		"d       \n</pre>"
+	if got := buf.String(); !strings.Contains(got, want) {
+		t.Errorf("got = %s\n\nwant it to contain %s", got, want)
+	}
+}
+
+func TestTestSurface_WriteSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := exportSurface().WriteSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Spaces without decorations aren't drawn.
+	want := `<svg xmlns="http://www.w3.org/2000/svg" width="67.2" height="34" viewBox="0 0 67.2 34" font-family="monospace" font-size="14">
+<rect width="100%" height="100%" fill="#000000"/>
+<rect x="16.8" y="0" width="16.8" height="17" fill="#e5e5e5"/>
+<rect x="33.6" y="0" width="8.4" height="17" fill="#ff5f00"/>
+<text x="0" y="13.6" textLength="16.8" lengthAdjust="spacingAndGlyphs" xml:space="preserve" fill="#ff0000" font-weight="bold">&lt;a</text>
+<text x="16.8" y="13.6" textLength="16.8" lengthAdjust="spacingAndGlyphs" xml:space="preserve" fill="#000000">世</text>
+<text x="33.6" y="13.6" textLength="8.4" lengthAdjust="spacingAndGlyphs" xml:space="preserve" fill="#e5e5e5" text-decoration="underline">b</text>
+<text x="42" y="13.6" textLength="8.4" lengthAdjust="spacingAndGlyphs" xml:space="preserve" fill="#e5e5e5" opacity="0.5">c</text>
+<text x="0" y="30.6" textLength="67.2" lengthAdjust="spacingAndGlyphs" xml:space="preserve" fill="#e5e5e5">d       </text>
+</svg>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got = \n%s\n\nwant = \n%s", got, want)
+	}
+}
+
+func TestWriteHTML_ANSISurface(t *testing.T) {
+	ts := exportSurface()
+	as := NewANSISurface(&bytes.Buffer{}, 8, 2, ANSIPlain)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 8; x++ {
+			if ch, style, ok := ts.Cell(x, y); ok {
+				as.SetCell(x, y, ch, style)
+			}
+		}
+	}
+
+	var want, got bytes.Buffer
+	if err := WriteHTML(&want, ts); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteHTML(&got, as); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("got = \n%s\n\nwant = \n%s", got.String(), want.String())
+	}
+}
+
+func TestPaletteHex(t *testing.T) {
+	for _, tt := range []struct {
+		index int
+		want  string
+	}{
+		{1, "#cd0000"},
+		{15, "#ffffff"},
+		{16, "#000000"},
+		{202, "#ff5f00"},
+		{231, "#ffffff"},
+		{232, "#080808"},
+		{255, "#eeeeee"},
+	} {
+		if got := paletteHex(tt.index); got != tt.want {
+			t.Errorf("%d: got = %s; want = %s", tt.index, got, tt.want)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package tui
 
+import "image"
+
 // FocusChain enables custom focus traversal when Tab or Backtab is pressed.
 type FocusChain interface {
 	FocusNext(w Widget) Widget
@@ -7,32 +9,289 @@ type FocusChain interface {
 	FocusDefault() Widget
 }
 
+// focusedPath returns the widgets from w down to the deepest focused widget
+// within it, or nil if no widget is focused.
+func focusedPath(w Widget) []Widget {
+	if c, ok := w.(Container); ok {
+		for _, child := range c.Children() {
+			if path := focusedPath(child); path != nil {
+				return append([]Widget{w}, path...)
+			}
+		}
+	}
+	if w.IsFocused() {
+		return []Widget{w}
+	}
+	return nil
+}
+
+// pathTo returns the widgets from root down to target, or nil if target is
+// not in the tree.
+func pathTo(root, target Widget) []Widget {
+	if root == target {
+		return []Widget{root}
+	}
+	if c, ok := root.(Container); ok {
+		for _, child := range c.Children() {
+			if path := pathTo(child, target); path != nil {
+				return append([]Widget{root}, path...)
+			}
+		}
+	}
+	return nil
+}
+
 type kbFocusController struct {
 	focusedWidget Widget
 
 	chain FocusChain
+
+	// onChange is called whenever the focus moves to another widget.
+	onChange func(from, to Widget)
+
+	// directional enables moving the focus with the arrow keys.
+	directional bool
+}
+
+// setFocus moves the focus to w, which may be nil to remove the focus.
+func (c *kbFocusController) setFocus(w Widget) {
+	from := c.focusedWidget
+	if from == w {
+		return
+	}
+	if from != nil {
+		from.SetFocused(false)
+	}
+	c.focusedWidget = w
+	if w != nil {
+		w.SetFocused(true)
+	}
+	if c.onChange != nil {
+		c.onChange(from, w)
+	}
+}
+
+// enabler is implemented by widgets that can be disabled, e.g. those built on
+// WidgetBase.
+type enabler interface {
+	IsEnabled() bool
 }
 
-func (c *kbFocusController) OnKeyEvent(e KeyEvent) {
+// isDisabled returns whether a widget has been disabled.
+func isDisabled(w Widget) bool {
+	e, ok := w.(enabler)
+	return ok && !e.IsEnabled()
+}
+
+// dropDisabled removes the focus from the focused widget if it, or a
+// container it's in, has been disabled. A widget drops its own focus when
+// it's disabled, but only the controller can tell the UI that the focus has
+// moved. It returns whether the focus was removed.
+func (c *kbFocusController) dropDisabled(root Widget) bool {
+	if c.focusedWidget == nil {
+		return false
+	}
+	path := pathTo(root, c.focusedWidget)
+	if path == nil {
+		path = []Widget{c.focusedWidget}
+	}
+	for _, w := range path {
+		if isDisabled(w) {
+			c.setFocus(nil)
+			return true
+		}
+	}
+	return false
+}
+
+// focusAt moves the focus to the deepest widget at the given point that is
+// part of the focus chain. A widget is part of the chain if the chain knows
+// which widget comes after it.
+func (c *kbFocusController) focusAt(root Widget, pt image.Point) {
 	if c.chain == nil {
 		return
 	}
+	ws := widgetsAt(root, pt)
+	for i := len(ws) - 1; i >= 0; i-- {
+		if c.chain.FocusNext(ws[i]) != nil {
+			c.setFocus(ws[i])
+			return
+		}
+	}
+}
+
+// path returns the widgets a key event propagates through, starting with the
+// focused widget and ending with the root. If the widget focused by the chain
+// contains other focused widgets, the deepest one gets the event first. If no
+// widget is focused, only the root gets the event.
+//
+// The parents of the focused widget are found through the Container
+// interface. If the focused widget can't be reached that way, e.g. because
+// it's inside a container that doesn't implement Container, the event goes
+// straight from the focused widget to the root.
+func (c *kbFocusController) path(root Widget) []Widget {
+	var path []Widget
+
+	if c.focusedWidget != nil {
+		sub := focusedPath(c.focusedWidget)
+		if sub == nil {
+			sub = []Widget{c.focusedWidget}
+		}
+		if parents := pathTo(root, c.focusedWidget); parents != nil {
+			path = append(parents[:len(parents)-1], sub...)
+		} else if c.focusedWidget != root {
+			path = append([]Widget{root}, sub...)
+		}
+	}
+	if path == nil {
+		path = focusedPath(root)
+	}
+	if path == nil {
+		return []Widget{root}
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+func (c *kbFocusController) OnKeyEvent(root Widget, e KeyEvent) {
+	if c.chain == nil {
+		return
+	}
+	var next Widget
 	switch e.Key {
+	case KeyUp, KeyDown, KeyLeft, KeyRight:
+		if c.directional && e.Modifiers&^ModAlt == 0 {
+			c.focusInDirection(root, arrowDirections[e.Key])
+		}
+		return
 	case KeyTab:
 		if c.focusedWidget != nil {
-			c.focusedWidget.SetFocused(false)
-			c.focusedWidget = c.chain.FocusNext(c.focusedWidget)
-			c.focusedWidget.SetFocused(true)
+			next = c.chain.FocusNext(c.focusedWidget)
 		}
 	case KeyBacktab:
 		if c.focusedWidget != nil {
-			c.focusedWidget.SetFocused(false)
-			c.focusedWidget = c.chain.FocusPrev(c.focusedWidget)
-			c.focusedWidget.SetFocused(true)
+			next = c.chain.FocusPrev(c.focusedWidget)
 		}
+	default:
+		return
+	}
+	// The focused widget may have left the chain, e.g. if it was removed
+	// from the tree.
+	if next == nil {
+		next = c.chain.FocusDefault()
+	}
+	if next != nil {
+		c.setFocus(next)
 	}
 }
 
+// skipsWidgets returns whether a key moves the focus without being delivered
+// to the focused widget first. That's Alt and an arrow key, while directional
+// focus is enabled.
+func (c *kbFocusController) skipsWidgets(e KeyEvent) bool {
+	_, arrow := arrowDirections[e.Key]
+	return c.directional && arrow && e.Modifiers == ModAlt
+}
+
+// arrowDirections maps the arrow keys to the direction they move the focus
+// in.
+var arrowDirections = map[Key]image.Point{
+	KeyUp:    {0, -1},
+	KeyDown:  {0, 1},
+	KeyLeft:  {-1, 0},
+	KeyRight: {1, 0},
+}
+
+// focusInDirection moves the focus to the widget of the chain that is nearest
+// to the focused widget in the given direction, based on where the widgets
+// are on the screen. Widgets that are straight ahead are preferred over those
+// further to the side. Ties go to the widget that comes first in the chain.
+func (c *kbFocusController) focusInDirection(root Widget, dir image.Point) {
+	bounds := make(map[Widget]image.Rectangle)
+	r := rootBounds(root)
+	walkWidgets(root, r, r, func(w Widget, _, visible image.Rectangle) {
+		bounds[w] = visible
+	})
+
+	from, ok := bounds[c.focusedWidget]
+	if !ok {
+		return
+	}
+
+	var (
+		next Widget
+		best = maxInt
+	)
+	for _, w := range chainWidgets(c.chain) {
+		to, ok := bounds[w]
+		if !ok || w == c.focusedWidget {
+			continue
+		}
+		if d, ok := directionalDistance(from, to, dir); ok && d < best {
+			next, best = w, d
+		}
+	}
+
+	if next != nil {
+		c.setFocus(next)
+	}
+}
+
+// chainWidgets returns the widgets of a focus chain, in focus order.
+func chainWidgets(chain FocusChain) []Widget {
+	if tc, ok := chain.(*TreeFocusChain); ok {
+		return tc.widgets()
+	}
+
+	var ws []Widget
+	seen := make(map[Widget]bool)
+	for w := chain.FocusDefault(); w != nil && !seen[w]; w = chain.FocusNext(w) {
+		seen[w] = true
+		ws = append(ws, w)
+	}
+	return ws
+}
+
+// directionalDistance returns how far the rectangle to is from the rectangle
+// from in the given direction, or false if it isn't in that direction at all.
+// The distance to the side counts twice as much as the distance ahead.
+func directionalDistance(from, to image.Rectangle, dir image.Point) (int, bool) {
+	var ahead, side int
+	switch dir {
+	case image.Pt(0, -1):
+		ahead = from.Min.Y - to.Max.Y
+		side = rangeDistance(from.Min.X, from.Max.X, to.Min.X, to.Max.X)
+	case image.Pt(0, 1):
+		ahead = to.Min.Y - from.Max.Y
+		side = rangeDistance(from.Min.X, from.Max.X, to.Min.X, to.Max.X)
+	case image.Pt(-1, 0):
+		ahead = from.Min.X - to.Max.X
+		side = rangeDistance(from.Min.Y, from.Max.Y, to.Min.Y, to.Max.Y)
+	case image.Pt(1, 0):
+		ahead = to.Min.X - from.Max.X
+		side = rangeDistance(from.Min.Y, from.Max.Y, to.Min.Y, to.Max.Y)
+	}
+	if ahead < 0 {
+		return 0, false
+	}
+	return ahead + 2*side, true
+}
+
+// rangeDistance returns the number of cells between the ranges [a0, a1) and
+// [b0, b1), or 0 if they overlap.
+func rangeDistance(a0, a1, b0, b1 int) int {
+	switch {
+	case b1 <= a0:
+		return a0 - b1 + 1
+	case a1 <= b0:
+		return b0 - a1 + 1
+	}
+	return 0
+}
+
 // DefaultFocusChain is the default focus chain.
 var DefaultFocusChain = &SimpleFocusChain{
 	widgets: make([]Widget, 0),
@@ -86,3 +345,85 @@ func (c *SimpleFocusChain) FocusDefault() Widget {
 	}
 	return c.widgets[0]
 }
+
+// TreeFocusChain moves the focus between the focusable widgets of a widget
+// tree, in the order they are laid out. The tree is walked whenever the focus
+// moves, so the chain follows widgets as they are added, removed, disabled or
+// hidden. Disabling a container, e.g. a Box, removes everything it contains
+// from the chain. Widgets without a size, or scrolled out of view, are hidden.
+type TreeFocusChain struct {
+	root Widget
+}
+
+// NewTreeFocusChain returns a focus chain of the focusable widgets in the
+// tree of the given root widget.
+func NewTreeFocusChain(root Widget) *TreeFocusChain {
+	return &TreeFocusChain{root: root}
+}
+
+// widgets returns the widgets that can currently be focused. Widgets inside
+// a widget that can't be focused, or inside a disabled container, are
+// skipped. Once the root has been laid out, so are the widgets that are
+// hidden, i.e. that have no size, or that are masked or scrolled out of view
+// by their containers.
+func (c *TreeFocusChain) widgets() []Widget {
+	var ws []Widget
+
+	r := rootBounds(c.root)
+	laidOut := !r.Empty()
+
+	var walk func(w Widget, bounds, visible image.Rectangle)
+	walk = func(w Widget, bounds, visible image.Rectangle) {
+		if isDisabled(w) || laidOut && visible.Empty() {
+			return
+		}
+		if f, ok := w.(Focusable); ok {
+			if !f.Focusable() {
+				return
+			}
+			ws = append(ws, w)
+		}
+		if cont, ok := w.(Container); ok {
+			for _, child := range cont.Children() {
+				cb := cont.ChildBounds(child).Add(bounds.Min)
+				walk(child, cb, cb.Intersect(visible))
+			}
+		}
+	}
+	walk(c.root, r, r)
+
+	return ws
+}
+
+// FocusNext returns the focusable widget after the given widget, or nil if
+// the widget isn't focusable.
+func (c *TreeFocusChain) FocusNext(current Widget) Widget {
+	ws := c.widgets()
+	for i, w := range ws {
+		if w == current {
+			return ws[(i+1)%len(ws)]
+		}
+	}
+	return nil
+}
+
+// FocusPrev returns the focusable widget before the given widget, or nil if
+// the widget isn't focusable.
+func (c *TreeFocusChain) FocusPrev(current Widget) Widget {
+	ws := c.widgets()
+	for i, w := range ws {
+		if w == current {
+			return ws[(i+len(ws)-1)%len(ws)]
+		}
+	}
+	return nil
+}
+
+// FocusDefault returns the first focusable widget in the tree.
+func (c *TreeFocusChain) FocusDefault() Widget {
+	ws := c.widgets()
+	if len(ws) == 0 {
+		return nil
+	}
+	return ws[0]
+}
@@ -0,0 +1,368 @@
+package tui
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestUI_OnFocusChanged(t *testing.T) {
+	user := NewEntry()
+	pass := NewEntry()
+
+	var got []string
+	user.OnFocusIn(func() {
+		got = append(got, "user in")
+	})
+	user.OnFocusOut(func() {
+		got = append(got, "user out")
+	})
+	pass.OnFocusChanged(func(focused bool) {
+		got = append(got, fmt.Sprintf("pass %v", focused))
+	})
+
+	chain := &SimpleFocusChain{}
+	chain.Set(user, pass)
+
+	ui := NewTestUI(NewVBox(user, pass), 10, 2)
+	ui.OnFocusChanged(func(from, to Widget) {
+		got = append(got, fmt.Sprintf("ui %s->%s", entryName(user, pass, from), entryName(user, pass, to)))
+	})
+	ui.SetFocusChain(chain)
+	defer runTestUI(t, ui)()
+
+	if err := ui.Type("<Tab><Backtab>"); err != nil {
+		t.Fatal(err)
+	}
+	ui.WaitIdle()
+
+	want := []string{
+		"user in", "ui nil->user",
+		"user out", "pass true", "ui user->pass",
+		"pass false", "user in", "ui pass->user",
+	}
+
+	ui.Update(func() {
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("got = %q; want = %q", got, want)
+		}
+	})
+}
+
+func entryName(user, pass *Entry, w Widget) string {
+	switch w {
+	case nil:
+		return "nil"
+	case user:
+		return "user"
+	case pass:
+		return "pass"
+	}
+	return "?"
+}
+
+func TestUI_ClickToFocus(t *testing.T) {
+	user := NewEntry()
+	pass := NewEntry()
+	label := NewLabel("label")
+
+	chain := &SimpleFocusChain{}
+	chain.Set(user, pass)
+
+	root := NewVBox(
+		NewPadder(1, 0, user),
+		NewPadder(1, 0, pass),
+		label,
+	)
+
+	ui := NewTestUI(root, 10, 3)
+	ui.SetFocusChain(chain)
+	defer runTestUI(t, ui)()
+
+	for _, tt := range []struct {
+		test string
+		x, y int
+		want *Entry
+	}{
+		{"second entry", 3, 1, pass},
+		{"padding", 0, 0, pass},
+		{"not in chain", 2, 2, pass},
+		{"first entry", 1, 0, user},
+	} {
+		ui.Click(tt.x, tt.y)
+		ui.WaitIdle()
+
+		ui.Update(func() {
+			if !tt.want.IsFocused() || ui.kbFocus.focusedWidget != tt.want {
+				t.Errorf("%s: expected %s to be focused", tt.test, entryName(user, pass, tt.want))
+			}
+			if user.IsFocused() && pass.IsFocused() {
+				t.Errorf("%s: expected only one entry to be focused", tt.test)
+			}
+		})
+	}
+}
+
+func TestTreeFocusChain(t *testing.T) {
+	a := NewEntry()
+	b := NewButton("b")
+	c := NewList()
+	d := NewTextEdit()
+
+	inner := NewHBox(b, NewLabel("label"), c)
+	root := NewVBox(a, NewPadder(1, 1, inner), d)
+
+	chain := NewTreeFocusChain(root)
+
+	names := map[Widget]string{a: "a", b: "b", c: "c", d: "d", nil: "nil"}
+
+	for _, tt := range []struct {
+		test    string
+		setup   func()
+		def     string
+		current Widget
+		next    string
+		prev    string
+	}{
+		{"document order", func() {}, "a", b, "c", "a"},
+		{"wrap around", func() {}, "a", d, "a", "c"},
+		{"not focusable", func() {}, "a", inner, "nil", "nil"},
+		{"disabled", func() { a.SetEnabled(false) }, "b", d, "b", "c"},
+		{"disabled current", func() {}, "b", a, "nil", "nil"},
+		{"enabled again", func() { a.SetEnabled(true) }, "a", d, "a", "c"},
+		{"disabled container", func() { inner.SetEnabled(false) }, "a", a, "d", "d"},
+		{"enabled container", func() { inner.SetEnabled(true) }, "a", d, "a", "c"},
+		{"removed", func() { inner.Remove(0) }, "a", a, "c", "d"},
+		{"appended", func() { root.Append(b) }, "a", d, "b", "c"},
+	} {
+		tt.setup()
+
+		if got := names[chain.FocusDefault()]; got != tt.def {
+			t.Errorf("%s: FocusDefault() = %s; want = %s", tt.test, got, tt.def)
+		}
+		if got := names[chain.FocusNext(tt.current)]; got != tt.next {
+			t.Errorf("%s: FocusNext() = %s; want = %s", tt.test, got, tt.next)
+		}
+		if got := names[chain.FocusPrev(tt.current)]; got != tt.prev {
+			t.Errorf("%s: FocusPrev() = %s; want = %s", tt.test, got, tt.prev)
+		}
+	}
+}
+
+func TestTreeFocusChain_Hidden(t *testing.T) {
+	a := NewEntry()
+	b := NewEntry()
+	c := NewEntry()
+	d := NewEntry()
+
+	s := NewScrollArea(NewVBox(c, d))
+	root := NewVBox(a, b, s)
+
+	names := map[Widget]string{a: "a", b: "b", c: "c", d: "d"}
+	chain := NewTreeFocusChain(root)
+
+	for _, tt := range []struct {
+		test  string
+		setup func()
+		want  string
+	}{
+		{"not laid out", func() {}, "a,b,c,d"},
+		{"visible", func() { root.Resize(image.Pt(5, 4)) }, "a,b,c,d"},
+		{"scrolled out", func() { s.Scroll(0, 1) }, "a,b,d"},
+		{"no size", func() { b.Resize(image.Pt(5, 0)) }, "a,d"},
+	} {
+		tt.setup()
+
+		var got []string
+		for _, w := range chain.widgets() {
+			got = append(got, names[w])
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("%s: got = %s; want = %s", tt.test, strings.Join(got, ","), tt.want)
+		}
+	}
+}
+
+func TestUI_TreeFocusChain(t *testing.T) {
+	a := NewEntry()
+	b := NewEntry()
+	root := NewVBox(a, b)
+
+	ui := NewTestUI(root, 10, 3)
+	ui.SetFocusChain(NewTreeFocusChain(root))
+	defer runTestUI(t, ui)()
+
+	c := NewEntry()
+	ui.Update(func() {
+		root.Append(c)
+		b.SetEnabled(false)
+	})
+
+	if err := ui.Type("a<Tab>c<Tab>a"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tab moves the focus from a removed widget to the first widget.
+	ui.Update(func() {
+		root.Remove(0)
+	})
+	if err := ui.Type("<Tab>c"); err != nil {
+		t.Fatal(err)
+	}
+	ui.WaitIdle()
+
+	ui.Update(func() {
+		if got := a.Text() + b.Text() + c.Text(); got != "aacc" {
+			t.Errorf("got = %q; want = %q", got, "aacc")
+		}
+		if !c.IsFocused() || b.IsFocused() {
+			t.Errorf("expected the last entry to be focused")
+		}
+	})
+}
+
+func TestUI_DisableFocused(t *testing.T) {
+	a := NewEntry()
+	b := NewEntry()
+	box := NewVBox(b)
+	root := NewVBox(a, box)
+
+	var got []string
+	ui := NewTestUI(root, 10, 2)
+	ui.SetFocusChain(NewTreeFocusChain(root))
+	ui.OnFocusChanged(func(from, to Widget) {
+		got = append(got, entryName(a, b, from)+"->"+entryName(a, b, to))
+	})
+	defer runTestUI(t, ui)()
+
+	// Disabling the focused widget, or a container it's in, removes the
+	// focus through the UI.
+	ui.Update(func() { a.SetEnabled(false) })
+	if err := ui.Type("x<Tab>y"); err != nil {
+		t.Fatal(err)
+	}
+	ui.Update(func() { box.SetEnabled(false) })
+	if err := ui.Type("z"); err != nil {
+		t.Fatal(err)
+	}
+	ui.WaitIdle()
+
+	want := "user->nil,nil->pass,pass->nil"
+	ui.Update(func() {
+		if strings.Join(got, ",") != want {
+			t.Errorf("got = %q; want = %q", strings.Join(got, ","), want)
+		}
+		if a.Text()+b.Text() != "y" {
+			t.Errorf("got = %q; want = %q", a.Text()+b.Text(), "y")
+		}
+		if b.IsFocused() {
+			t.Errorf("expected the entry in the disabled box to lose its focus")
+		}
+	})
+}
+
+func TestDirectionalDistance(t *testing.T) {
+	from := image.Rect(10, 10, 20, 12)
+
+	for _, tt := range []struct {
+		test string
+		to   image.Rectangle
+		dir  image.Point
+		dist int
+		ok   bool
+	}{
+		{"adjacent right", image.Rect(20, 10, 30, 12), image.Pt(1, 0), 0, true},
+		{"right and below", image.Rect(25, 13, 30, 14), image.Pt(1, 0), 5 + 2*2, true},
+		{"overlapping", image.Rect(15, 10, 30, 12), image.Pt(1, 0), 0, false},
+		{"behind", image.Rect(0, 10, 10, 12), image.Pt(1, 0), 0, false},
+		{"above", image.Rect(0, 0, 12, 5), image.Pt(0, -1), 5, true},
+		{"below to the left", image.Rect(0, 14, 5, 15), image.Pt(0, 1), 2 + 2*6, true},
+		{"left", image.Rect(0, 11, 8, 20), image.Pt(-1, 0), 2, true},
+	} {
+		dist, ok := directionalDistance(from, tt.to, tt.dir)
+		if dist != tt.dist || ok != tt.ok {
+			t.Errorf("%s: got = %d, %v; want = %d, %v", tt.test, dist, ok, tt.dist, tt.ok)
+		}
+	}
+}
+
+func TestUI_DirectionalFocus(t *testing.T) {
+	a := NewButton("a")
+	b := NewButton("b")
+	c := NewButton("c")
+	d := NewButton("d")
+	e := NewEntry()
+
+	grid := NewGrid(2, 1)
+	grid.SetCell(image.Pt(0, 0), c)
+	grid.SetCell(image.Pt(1, 0), d)
+
+	root := NewVBox(
+		NewHBox(a, NewPadder(1, 0, b)),
+		grid,
+		e,
+	)
+
+	names := map[Widget]string{a: "a", b: "b", c: "c", d: "d", e: "e"}
+
+	ui := NewTestUI(root, 20, 3)
+	ui.SetFocusChain(NewTreeFocusChain(root))
+	ui.SetDirectionalFocus(true)
+
+	var got []string
+	ui.OnFocusChanged(func(from, to Widget) {
+		got = append(got, names[to])
+	})
+	defer runTestUI(t, ui)()
+
+	// The entry uses the plain left and right arrows, but not Alt+Up.
+	if err := ui.Type("<Right><Down><Left><Up><Up><Down><Down><Right><Alt+Up>"); err != nil {
+		t.Fatal(err)
+	}
+	ui.WaitIdle()
+
+	want := "b,d,c,a,c,e,c"
+	ui.Update(func() {
+		if strings.Join(got, ",") != want {
+			t.Errorf("got = %q; want = %q", strings.Join(got, ","), want)
+		}
+	})
+}
+
+func TestUI_DirectionalFocusAltArrows(t *testing.T) {
+	a := NewButton("a")
+	e := NewEntry()
+	e.SetText("ab")
+	root := NewHBox(a, e)
+
+	ui := NewTestUI(root, 20, 1)
+	ui.SetFocusChain(NewTreeFocusChain(root))
+	defer runTestUI(t, ui)()
+
+	// The entry handles Alt+Left as Left while directional focus is off.
+	ui.Update(func() {
+		ui.kbFocus.setFocus(e)
+	})
+	if err := ui.Type("<Alt+Left>x"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once it's on, Alt+Left moves the focus instead.
+	ui.Update(func() {
+		ui.SetDirectionalFocus(true)
+	})
+	if err := ui.Type("<Alt+Left>"); err != nil {
+		t.Fatal(err)
+	}
+	ui.WaitIdle()
+
+	ui.Update(func() {
+		if got := e.Text(); got != "axb" {
+			t.Errorf("got = %q; want = %q", got, "axb")
+		}
+		if !a.IsFocused() {
+			t.Errorf("expected a to be focused")
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 )
 
 var _ Widget = &Grid{}
+var _ Container = &Grid{}
 
 // Grid is a widget that lays out widgets in a grid.
 type Grid struct {
@@ -25,13 +26,15 @@ type Grid struct {
 
 // NewGrid returns a new Grid.
 func NewGrid(cols, rows int) *Grid {
-	return &Grid{
+	g := &Grid{
 		cols:          cols,
 		rows:          rows,
 		cells:         make(map[image.Point]Widget),
 		columnStretch: make(map[int]int),
 		rowStretch:    make(map[int]int),
 	}
+	g.trackDirtyFor(g)
+	return g
 }
 
 // Draw draws the grid.
@@ -90,7 +93,7 @@ func (g *Grid) Draw(p *Painter) {
 					Min: image.Point{},
 					Max: w.Size(),
 				}, func(p *Painter) {
-					w.Draw(p)
+					p.DrawWidget(w)
 				})
 				p.Restore()
 			}
@@ -160,7 +163,7 @@ func (g *Grid) SizeHint() image.Point {
 // Resize is called by the layout engine and is not intended to be used by end
 // users.
 func (g *Grid) Resize(size image.Point) {
-	g.size = size
+	g.WidgetBase.Resize(size)
 	inner := g.size
 	if g.hasBorder {
 		inner.X = g.size.X - (g.cols + 1)
@@ -392,25 +395,50 @@ func (g *Grid) minColumnWidth(i int) int {
 	return result
 }
 
-// OnKeyEvent handles key events.
-func (g *Grid) OnKeyEvent(ev KeyEvent) {
-	for _, w := range g.cells {
-		w.OnKeyEvent(ev)
+// Children returns the widgets in the grid, row by row.
+func (g *Grid) Children() []Widget {
+	var ws []Widget
+	for j := 0; j < g.rows; j++ {
+		for i := 0; i < g.cols; i++ {
+			if w, ok := g.cells[image.Point{i, j}]; ok {
+				ws = append(ws, w)
+			}
+		}
+	}
+	return ws
+}
+
+// ChildBounds returns the area occupied by the given child widget.
+func (g *Grid) ChildBounds(w Widget) image.Rectangle {
+	for pos, c := range g.cells {
+		if c != w {
+			continue
+		}
+		// The grid has not been laid out with this cell yet.
+		if pos.X >= len(g.colWidths) || pos.Y >= len(g.rowHeights) {
+			return image.Rectangle{}
+		}
+		wp := g.mapCellToLocal(pos)
+		return image.Rectangle{Min: wp, Max: wp.Add(w.Size())}
 	}
+	return image.Rectangle{}
 }
 
 // SetCell sets or replaces the contents of a cell.
 func (g *Grid) SetCell(pos image.Point, w Widget) {
+	g.MarkDirty()
 	g.cells[pos] = w
 }
 
 // SetBorder sets whether the border is visible or not.
 func (g *Grid) SetBorder(enabled bool) {
+	g.MarkDirty()
 	g.hasBorder = enabled
 }
 
 // AppendRow adds a new row at the end.
 func (g *Grid) AppendRow(row ...Widget) {
+	g.MarkDirty()
 	g.rows++
 
 	if len(row) > g.cols {
@@ -425,6 +453,7 @@ func (g *Grid) AppendRow(row ...Widget) {
 
 // RemoveRow removes the row ( at index ) from the grid
 func (g *Grid) RemoveRow(index int) {
+	g.MarkDirty()
 	if index < g.rows {
 		g.rows--
 		for i := index; i <= g.rows; i++ {
@@ -441,6 +470,7 @@ func (g *Grid) RemoveRow(index int) {
 
 // RemoveRows will remove all the rows in grid
 func (g *Grid) RemoveRows() {
+	g.MarkDirty()
 	g.rows = 0
 	g.cells = make(map[image.Point]Widget)
 }
@@ -452,11 +482,13 @@ func (g *Grid) RemoveRows() {
 // SetColumnStretch(1, 2), the second column will fill up twice as much space
 // as the first one.
 func (g *Grid) SetColumnStretch(col, stretch int) {
+	g.MarkDirty()
 	g.columnStretch[col] = stretch
 }
 
 // SetRowStretch sets the stretch factor for a given row. For more on stretch
 // factors, see SetColumnStretch.
 func (g *Grid) SetRowStretch(row, stretch int) {
+	g.MarkDirty()
 	g.rowStretch[row] = stretch
 }
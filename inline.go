@@ -0,0 +1,22 @@
+package tui
+
+import "os"
+
+// newInlineUI returns a UI that draws in the lines below the cursor of the
+// terminal returned by open.
+func newInlineUI(root Widget, height int, open func() (*os.File, error)) *tcellUI {
+	screen := &ttyScreen{open: open}
+
+	s := &ttySurface{
+		ANSISurface: NewANSISurface(screen, 0, 0, ANSIInline),
+		screen:      screen,
+		height:      height,
+	}
+	screen.surface = s.ANSISurface
+
+	ui := newScreenUI(root, screen, s)
+	ui.handleSignals = true
+	ui.jobControl = len(jobControlSignals) > 0
+
+	return ui
+}
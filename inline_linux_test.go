@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"image"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestInlineUI(t *testing.T) {
+	master, name := openPTY(t, 20, 10)
+	out := readPTY(master)
+
+	entry := NewEntry()
+	entry.SetFocused(true)
+	root := NewVBox(NewLabel("Name:"), entry)
+
+	ui := newInlineUI(root, 2, func() (*os.File, error) {
+		return os.OpenFile(name, os.O_RDWR, 0)
+	})
+
+	resized := make(chan image.Point, 1)
+	ui.OnResize(func(size image.Point) {
+		resized <- size
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ui.Run()
+	}()
+
+	waitFor(t, "first frame", func() bool {
+		return strings.Contains(out.String(), "Name:")
+	})
+
+	master.Write([]byte("hi\x1b[D!"))
+	waitFor(t, "typed text", func() bool {
+		var text string
+		ui.Update(func() {
+			text = entry.Text()
+		})
+		return text == "h!i"
+	})
+
+	var size image.Point
+	ui.Update(func() {
+		size = ui.Size()
+	})
+	if size != image.Pt(20, 2) {
+		t.Errorf("got = %v; want = %v", size, image.Pt(20, 2))
+	}
+
+	// The surface follows the width of the terminal.
+	if err := unix.IoctlSetWinsize(int(master.Fd()), unix.TIOCSWINSZ, &unix.Winsize{Col: 30, Row: 10}); err != nil {
+		t.Fatal(err)
+	}
+	syscall.Kill(syscall.Getpid(), syscall.SIGWINCH)
+
+	select {
+	case got := <-resized:
+		if got != image.Pt(30, 2) {
+			t.Errorf("got = %v; want = %v", got, image.Pt(30, 2))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for resize")
+	}
+
+	ui.Quit()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// The cursor is left on the line below the last frame.
+	detach := "\x1b[0m\r\x1b[1G\n\x1b[?25h"
+	waitFor(t, "last frame", func() bool {
+		return strings.HasSuffix(out.String(), detach)
+	})
+	got := out.String()
+
+	if !strings.Contains(got, "Name:") || !strings.Contains(got, "h!i") {
+		t.Errorf("expected frames in output: %q", got)
+	}
+	// Neither the screen nor the lines above are touched.
+	for _, seq := range []string{"\x1b[2J", "\x1b[?1049h", "\x1b[H"} {
+		if strings.Contains(got, seq) {
+			t.Errorf("unexpected %q in output: %q", seq, got)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell"
+)
+
+// inputDecoder turns the bytes read from a terminal into key and mouse events,
+// without a terminfo database. It understands the escape sequences sent by
+// xterm and the terminals compatible with it, which is nearly all of them.
+// Events are reported the same way as by tcell, so that the rest of the UI
+// can't tell the difference; an escape followed by anything but a known
+// sequence is read as Alt, for instance.
+type inputDecoder struct {
+	buf []byte
+}
+
+// The keys sent as "ESC [ <final>" or "ESC O <final>".
+var csiFinalKeys = map[byte]tcell.Key{
+	'A': tcell.KeyUp,
+	'B': tcell.KeyDown,
+	'C': tcell.KeyRight,
+	'D': tcell.KeyLeft,
+	'H': tcell.KeyHome,
+	'F': tcell.KeyEnd,
+	'Z': tcell.KeyBacktab,
+	'P': tcell.KeyF1,
+	'Q': tcell.KeyF2,
+	'R': tcell.KeyF3,
+	'S': tcell.KeyF4,
+}
+
+// The keys sent as "ESC [ <number> ~".
+var csiTildeKeys = map[int]tcell.Key{
+	1:  tcell.KeyHome,
+	2:  tcell.KeyInsert,
+	3:  tcell.KeyDelete,
+	4:  tcell.KeyEnd,
+	5:  tcell.KeyPgUp,
+	6:  tcell.KeyPgDn,
+	7:  tcell.KeyHome,
+	8:  tcell.KeyEnd,
+	11: tcell.KeyF1,
+	12: tcell.KeyF2,
+	13: tcell.KeyF3,
+	14: tcell.KeyF4,
+	15: tcell.KeyF5,
+	17: tcell.KeyF6,
+	18: tcell.KeyF7,
+	19: tcell.KeyF8,
+	20: tcell.KeyF9,
+	21: tcell.KeyF10,
+	23: tcell.KeyF11,
+	24: tcell.KeyF12,
+}
+
+// feed decodes the bytes, and returns the events that are complete. The
+// beginning of an escape sequence is held until the rest of it arrives, or
+// flush is called.
+func (d *inputDecoder) feed(b []byte) []tcell.Event {
+	d.buf = append(d.buf, b...)
+	return d.decode(false)
+}
+
+// waiting returns whether bytes are being held back.
+func (d *inputDecoder) waiting() bool {
+	return len(d.buf) > 0
+}
+
+// flush decodes the bytes held back, once it's clear that no more of the
+// sequence is coming. A lone escape is the Esc key.
+func (d *inputDecoder) flush() []tcell.Event {
+	return d.decode(true)
+}
+
+func (d *inputDecoder) decode(expire bool) []tcell.Event {
+	var evs []tcell.Event
+	for len(d.buf) > 0 {
+		ev, n := decodeKey(d.buf, expire)
+		if n == 0 {
+			break
+		}
+		d.buf = d.buf[n:]
+		if ev != nil {
+			evs = append(evs, ev)
+		}
+	}
+	if len(d.buf) == 0 {
+		d.buf = nil
+	}
+	return evs
+}
+
+// decodeKey decodes the first key in b, and returns it along with the number
+// of bytes it took up. It returns 0 bytes if b only holds the beginning of a
+// key, unless expire is set. The event is nil for bytes that are skipped.
+func decodeKey(b []byte, expire bool) (tcell.Event, int) {
+	if b[0] != '\x1b' {
+		return decodeRune(b, ModNone, expire)
+	}
+
+	if len(b) == 1 {
+		if !expire {
+			return nil, 0
+		}
+		return tcell.NewEventKey(tcell.KeyEsc, 0, tcell.ModNone), 1
+	}
+
+	switch b[1] {
+	case '[':
+		if ev, n, ok := decodeCSI(b); ok {
+			return ev, n
+		} else if n == 0 && !expire {
+			return nil, 0
+		}
+	case 'O':
+		if len(b) == 2 && !expire {
+			return nil, 0
+		}
+		if len(b) > 2 {
+			if k, ok := csiFinalKeys[b[2]]; ok {
+				return tcell.NewEventKey(k, 0, tcell.ModNone), 3
+			}
+		}
+	case '\x1b':
+		// A lone escape followed by another sequence.
+		return tcell.NewEventKey(tcell.KeyEsc, 0, tcell.ModNone), 1
+	}
+
+	// Anything else is Alt and the next key, like in tcell.
+	ev, n := decodeRune(b[1:], ModAlt, expire)
+	if n == 0 {
+		return nil, 0
+	}
+	return ev, n + 1
+}
+
+// decodeRune decodes a character, or a control character.
+func decodeRune(b []byte, mod ModMask, expire bool) (tcell.Event, int) {
+	if b[0] < utf8.RuneSelf {
+		return tcell.NewEventKey(tcell.KeyRune, rune(b[0]), tcell.ModMask(mod)), 1
+	}
+	if !utf8.FullRune(b) && !expire {
+		return nil, 0
+	}
+	r, n := utf8.DecodeRune(b)
+	if r == utf8.RuneError {
+		return nil, n
+	}
+	return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModMask(mod)), n
+}
+
+// decodeCSI decodes a control sequence, "ESC [" followed by parameters and a
+// final byte. It returns false if the sequence isn't a known key or mouse
+// event, along with 0 bytes if it isn't complete yet.
+func decodeCSI(b []byte) (tcell.Event, int, bool) {
+	end := -1
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			end = i
+			break
+		}
+		if b[i] < 0x20 || b[i] > 0x3f {
+			// Not a control sequence.
+			return nil, 1, false
+		}
+	}
+	if end < 0 {
+		return nil, 0, false
+	}
+
+	params := strings.Split(string(b[2:end]), ";")
+	n := end + 1
+
+	if strings.HasPrefix(params[0], "<") && (b[end] == 'M' || b[end] == 'm') {
+		params[0] = params[0][1:]
+		ev, ok := decodeMouse(params, b[end] == 'm')
+		return ev, n, ok
+	}
+
+	// The modifiers are sent as the second parameter, plus one.
+	mod := tcell.ModNone
+	if len(params) > 1 {
+		m, err := strconv.Atoi(params[1])
+		if err != nil || m < 1 {
+			return nil, n, false
+		}
+		mod = csiModifiers(m - 1)
+	}
+
+	if b[end] == '~' {
+		code, err := strconv.Atoi(params[0])
+		if err != nil {
+			return nil, n, false
+		}
+		if k, ok := csiTildeKeys[code]; ok {
+			return tcell.NewEventKey(k, 0, mod), n, true
+		}
+		return nil, n, false
+	}
+
+	if k, ok := csiFinalKeys[b[end]]; ok {
+		return tcell.NewEventKey(k, 0, mod), n, true
+	}
+	return nil, n, false
+}
+
+// decodeMouse decodes the parameters of a mouse event reported in the SGR
+// format, "ESC [ < button ; x ; y M", where the final M is an m when a button
+// is released.
+func decodeMouse(params []string, release bool) (tcell.Event, bool) {
+	if len(params) != 3 {
+		return nil, false
+	}
+	var v [3]int
+	for i, p := range params {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, false
+		}
+		v[i] = n
+	}
+	code, x, y := v[0], v[1]-1, v[2]-1
+
+	var mod tcell.ModMask
+	if code&4 != 0 {
+		mod |= tcell.ModShift
+	}
+	if code&8 != 0 {
+		mod |= tcell.ModAlt
+	}
+	if code&16 != 0 {
+		mod |= tcell.ModCtrl
+	}
+
+	// Like tcell, report the buttons held down after the event, which are
+	// none once a button is released, or when the mouse moves on its own.
+	btns := tcell.ButtonNone
+	switch {
+	case code&64 != 0:
+		btns = [...]tcell.ButtonMask{tcell.WheelUp, tcell.WheelDown, tcell.WheelLeft, tcell.WheelRight}[code&3]
+	case release:
+	case code&3 == 0:
+		btns = tcell.Button1
+	case code&3 == 1:
+		btns = tcell.Button2
+	case code&3 == 2:
+		btns = tcell.Button3
+	}
+
+	return tcell.NewEventMouse(x, y, btns, mod), true
+}
+
+// csiModifiers converts the modifier bits of a control sequence.
+func csiModifiers(m int) tcell.ModMask {
+	var mod tcell.ModMask
+	if m&1 != 0 {
+		mod |= tcell.ModShift
+	}
+	if m&2 != 0 {
+		mod |= tcell.ModAlt
+	}
+	if m&4 != 0 {
+		mod |= tcell.ModCtrl
+	}
+	if m&8 != 0 {
+		mod |= tcell.ModMeta
+	}
+	return mod
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+func decodedNames(evs []tcell.Event) string {
+	var names []string
+	for _, ev := range evs {
+		k := convertKeyEvent(ev.(*tcell.EventKey))
+		names = append(names, k.Name())
+	}
+	return strings.Join(names, " ")
+}
+
+func TestInputDecoder(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want string
+	}{
+		{"ab", "a b"},
+		{"å世", "å 世"},
+		{"\r\t\x7f\x08\x01", "Enter Tab Backspace2 Backspace Ctrl+A"},
+		{"\x1b", "Esc"},
+		{"\x1bx", "Alt+x"},
+		{"\x1b\x1b[A", "Esc Up"},
+		{"\x1b[A\x1b[B\x1b[C\x1b[D", "Up Down Right Left"},
+		{"\x1bOA\x1bOP", "Up F1"},
+		{"\x1b[1;5C\x1b[1;2H\x1b[1;3D", "Ctrl+Right Shift+Home Alt+Left"},
+		{"\x1b[3~\x1b[5;5~\x1b[15~\x1b[24~", "Delete Ctrl+PgUp F5 F12"},
+		{"\x1b[Z", "Backtab"},
+		// Unknown sequences are read as Alt+[ followed by the rest, like
+		// tcell does, which is how the paste markers are detected.
+		{"\x1b[200~a", "Alt+[ 2 0 0 ~ a"},
+	} {
+		var d inputDecoder
+		evs := d.feed([]byte(tt.in))
+		evs = append(evs, d.flush()...)
+
+		if got := decodedNames(evs); got != tt.want {
+			t.Errorf("%q: got = %q; want = %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestInputDecoder_Partial(t *testing.T) {
+	var d inputDecoder
+
+	// The beginning of a sequence is held until the rest of it arrives.
+	var evs []tcell.Event
+	for _, b := range []byte("a\x1b[1;5A世") {
+		evs = append(evs, d.feed([]byte{b})...)
+	}
+	if got, want := decodedNames(evs), "a Ctrl+Up 世"; got != want {
+		t.Errorf("got = %q; want = %q", got, want)
+	}
+
+	// Or until it's clear that no more is coming.
+	if evs := d.feed([]byte("\x1b")); len(evs) > 0 {
+		t.Errorf("expected escape to be held, got %q", decodedNames(evs))
+	}
+	if !d.waiting() {
+		t.Errorf("expected decoder to be waiting")
+	}
+	if got, want := decodedNames(d.flush()), "Esc"; got != want {
+		t.Errorf("got = %q; want = %q", got, want)
+	}
+}
+
+func TestInputDecoder_Mouse(t *testing.T) {
+	var d inputDecoder
+	evs := d.feed([]byte("\x1b[<0;3;2M\x1b[<32;4;2M\x1b[<0;4;2m\x1b[<65;1;1M\x1b[<18;5;6M\x1b[<35;2;2M"))
+
+	want := []MouseEvent{
+		{Pos: image.Pt(2, 1), Action: MousePress, Button: MouseButtonLeft},
+		{Pos: image.Pt(3, 1), Action: MouseDrag, Button: MouseButtonLeft},
+		{Pos: image.Pt(3, 1), Action: MouseRelease, Button: MouseButtonLeft},
+		{Pos: image.Pt(0, 0), Action: MouseWheel, Wheel: WheelDown},
+		{Pos: image.Pt(4, 5), Action: MousePress, Button: MouseButtonRight, Modifiers: ModCtrl},
+		{Pos: image.Pt(1, 1), Action: MouseRelease, Button: MouseButtonRight},
+	}
+	if len(evs) != len(want) {
+		t.Fatalf("got %d events; want %d", len(evs), len(want))
+	}
+
+	var buttons tcell.ButtonMask
+	for i, ev := range evs {
+		var got MouseEvent
+		got, buttons = convertMouseEvent(ev.(*tcell.EventMouse), buttons)
+		if got != want[i] {
+			t.Errorf("%d: got = %+v; want = %+v", i, got, want[i])
+		}
+	}
+}
@@ -1,14 +1,200 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+// DefaultChordTimeout is how long a UI waits for the next key of a
+// partially typed key sequence, unless changed with SetChordTimeout.
+const DefaultChordTimeout = time.Second
+
+// keybinding binds a key sequence to a handler. The sequence is one or more
+// key names separated by spaces, e.g. "Ctrl+X Ctrl+S" or "g g".
 type keybinding struct {
 	sequence string
 	handler  func()
 }
 
+// keys returns the names of the keys in the sequence.
+func (b *keybinding) keys() []string {
+	keys := strings.Fields(b.sequence)
+	if len(keys) == 0 {
+		// A binding for the space key itself.
+		return []string{b.sequence}
+	}
+	for i, k := range keys {
+		if strings.EqualFold(k, "Space") {
+			keys[i] = " "
+		}
+	}
+	return keys
+}
+
 func (b *keybinding) match(ev KeyEvent) bool {
-	return strings.EqualFold(b.sequence, ev.Name())
+	return b.matchSequence([]KeyEvent{ev}) == matchFull
+}
+
+type matchResult int
+
+const (
+	matchNone matchResult = iota
+	matchPrefix
+	matchFull
+)
+
+// matchSequence returns whether the key events match the whole key sequence,
+// or only the beginning of it.
+func (b *keybinding) matchSequence(evs []KeyEvent) matchResult {
+	keys := b.keys()
+	if len(evs) > len(keys) {
+		return matchNone
+	}
+	for i, ev := range evs {
+		if !strings.EqualFold(keys[i], ev.Name()) {
+			return matchNone
+		}
+	}
+	if len(evs) < len(keys) {
+		return matchPrefix
+	}
+	return matchFull
+}
+
+// matchKeybindings returns the keybindings that match the key events, and
+// whether any keybinding expects more keys to follow.
+func matchKeybindings(bindings []*keybinding, evs []KeyEvent) (full []*keybinding, partial bool) {
+	for _, b := range bindings {
+		switch b.matchSequence(evs) {
+		case matchFull:
+			full = append(full, b)
+		case matchPrefix:
+			partial = true
+		}
+	}
+	return full, partial
+}
+
+// keySequenceName returns the key sequence of the key events, as written in
+// a keybinding.
+func keySequenceName(evs []KeyEvent) string {
+	names := make([]string, len(evs))
+	for i, ev := range evs {
+		names[i] = ev.Name()
+	}
+	return strings.Join(names, " ")
+}
+
+// checkKeybinding returns an error if the key sequence of b is invalid, or if
+// it conflicts with one of the other keybindings.
+func checkKeybinding(b *keybinding, others []*keybinding) error {
+	keys := b.keys()
+	for _, k := range keys {
+		if _, err := parseKey(k); err != nil {
+			return fmt.Errorf("invalid keybinding %q: %v", b.sequence, err)
+		}
+	}
+
+	for _, o := range others {
+		okeys := o.keys()
+
+		n := len(keys)
+		if len(okeys) < n {
+			n = len(okeys)
+		}
+		if !equalKeys(keys[:n], okeys[:n]) {
+			continue
+		}
+
+		switch {
+		case len(keys) == len(okeys):
+			return fmt.Errorf("keybinding %q is bound more than once", b.sequence)
+		case len(keys) < len(okeys):
+			return fmt.Errorf("keybinding %q is a prefix of %q, and only runs after the chord timeout", b.sequence, o.sequence)
+		default:
+			return fmt.Errorf("keybinding %q is a prefix of %q, and only runs after the chord timeout", o.sequence, b.sequence)
+		}
+	}
+
+	return nil
+}
+
+func equalKeys(a, b []string) bool {
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// keysByName maps the lowercase names of named keys to the keys.
+var keysByName = func() map[string]Key {
+	m := make(map[string]Key, len(keyNames))
+	for k, n := range keyNames {
+		m[strings.ToLower(n)] = k
+	}
+	return m
+}()
+
+// parseKey returns the KeyEvent for a key description as returned by
+// KeyEvent.Name, e.g. "a", "Enter" or "Ctrl+X".
+func parseKey(name string) (KeyEvent, error) {
+	var ev KeyEvent
+
+	parts := strings.Split(name, "+")
+	base := parts[len(parts)-1]
+
+	// Allow the plus key itself, e.g. "+" or "Alt++".
+	if base == "" && len(parts) > 1 {
+		parts = parts[:len(parts)-1]
+		base = "+"
+	}
+
+	for _, m := range parts[:len(parts)-1] {
+		switch strings.ToLower(m) {
+		case "shift":
+			ev.Modifiers |= ModShift
+		case "ctrl":
+			ev.Modifiers |= ModCtrl
+		case "alt":
+			ev.Modifiers |= ModAlt
+		case "meta":
+			ev.Modifiers |= ModMeta
+		default:
+			return KeyEvent{}, fmt.Errorf("unknown modifier %q in key %q", m, name)
+		}
+	}
+
+	if ev.Modifiers&ModCtrl != 0 {
+		if k, ok := keysByName["ctrl-"+strings.ToLower(base)]; ok {
+			ev.Key = k
+			ev.Rune = rune(k)
+			return ev, nil
+		}
+	}
+
+	if strings.EqualFold(base, "Space") {
+		ev.Key = KeyRune
+		ev.Rune = ' '
+		return ev, nil
+	}
+
+	if k, ok := keysByName[strings.ToLower(base)]; ok {
+		ev.Key = k
+		if k < KeyRune {
+			ev.Rune = rune(k)
+		}
+		return ev, nil
+	}
+
+	if r, size := utf8.DecodeRuneInString(base); size > 0 && size == len(base) {
+		ev.Key = KeyRune
+		ev.Rune = r
+		return ev, nil
+	}
+
+	return KeyEvent{}, fmt.Errorf("unknown key %q", name)
 }
@@ -1,6 +1,9 @@
 package tui
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestKeybinding_Match(t *testing.T) {
 	for _, tt := range []struct {
@@ -22,3 +25,97 @@ func TestKeybinding_Match(t *testing.T) {
 		})
 	}
 }
+
+func TestParseKey(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want KeyEvent
+	}{
+		{"a", KeyEvent{Key: KeyRune, Rune: 'a'}},
+		{"å", KeyEvent{Key: KeyRune, Rune: 'å'}},
+		{"Enter", KeyEvent{Key: KeyEnter, Rune: rune(KeyEnter)}},
+		{"esc", KeyEvent{Key: KeyEsc, Rune: rune(KeyEsc)}},
+		{"Up", KeyEvent{Key: KeyUp}},
+		{"Ctrl+X", KeyEvent{Key: KeyCtrlX, Rune: rune(KeyCtrlX), Modifiers: ModCtrl}},
+		{"Ctrl+Space", KeyEvent{Key: KeyCtrlSpace, Rune: rune(KeyCtrlSpace), Modifiers: ModCtrl}},
+		{"Shift+Alt+Left", KeyEvent{Key: KeyLeft, Modifiers: ModAlt | ModShift}},
+		{"Alt++", KeyEvent{Key: KeyRune, Rune: '+', Modifiers: ModAlt}},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseKey(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got = %+v; want = %+v", got, tt.want)
+			}
+			if !strings.EqualFold(got.Name(), tt.name) {
+				t.Errorf("got = %q; want = %q", got.Name(), tt.name)
+			}
+		})
+	}
+
+	for _, name := range []string{"", "Hyper+a", "NoSuchKey"} {
+		if _, err := parseKey(name); err == nil {
+			t.Errorf("expected error for %q", name)
+		}
+	}
+}
+
+func TestKeybinding_MatchSequence(t *testing.T) {
+	ctrlX := KeyEvent{Key: KeyCtrlX, Rune: rune(KeyCtrlX), Modifiers: ModCtrl}
+	ctrlS := KeyEvent{Key: KeyCtrlS, Rune: rune(KeyCtrlS), Modifiers: ModCtrl}
+	g := KeyEvent{Key: KeyRune, Rune: 'g'}
+	space := KeyEvent{Key: KeyRune, Rune: ' '}
+
+	for _, tt := range []struct {
+		sequence string
+		events   []KeyEvent
+		want     matchResult
+	}{
+		{"Ctrl+X Ctrl+S", []KeyEvent{ctrlX}, matchPrefix},
+		{"Ctrl+X Ctrl+S", []KeyEvent{ctrlX, ctrlS}, matchFull},
+		{"Ctrl+X Ctrl+S", []KeyEvent{ctrlS}, matchNone},
+		{"Ctrl+X Ctrl+S", []KeyEvent{ctrlX, ctrlS, g}, matchNone},
+		{"g  g", []KeyEvent{g, g}, matchFull},
+		{"g", []KeyEvent{g, g}, matchNone},
+		{" ", []KeyEvent{space}, matchFull},
+		{"Ctrl+X Space", []KeyEvent{ctrlX, space}, matchFull},
+	} {
+		tt := tt
+		t.Run(tt.sequence, func(t *testing.T) {
+			b := &keybinding{sequence: tt.sequence}
+			if got := b.matchSequence(tt.events); got != tt.want {
+				t.Errorf("got = %v; want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckKeybinding(t *testing.T) {
+	existing := []*keybinding{
+		{sequence: "Ctrl+X Ctrl+S"},
+		{sequence: "g g"},
+	}
+
+	for _, tt := range []struct {
+		sequence string
+		valid    bool
+	}{
+		{"Ctrl+X Ctrl+C", true},
+		{"g h", true},
+		{"ctrl+x ctrl+s", false},
+		{"Ctrl+X", false},
+		{"g g g", false},
+		{"Ctrl+X NoSuchKey", false},
+	} {
+		tt := tt
+		t.Run(tt.sequence, func(t *testing.T) {
+			err := checkKeybinding(&keybinding{sequence: tt.sequence}, existing)
+			if (err == nil) != tt.valid {
+				t.Errorf("got = %v; want valid = %v", err, tt.valid)
+			}
+		})
+	}
+}
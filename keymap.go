@@ -0,0 +1,81 @@
+package tui
+
+// Keymap is a named set of keybindings.
+//
+// Besides the global keybindings set on the UI, a widget can be given a
+// keymap with SetKeymap. Its keybindings apply only while the widget, or a
+// widget inside it, has focus. When a key is pressed, the keymap of the
+// focused widget is tried first, followed by the keymaps of the containers
+// it's in, and finally the global keybindings.
+type Keymap struct {
+	name     string
+	bindings []*keybinding
+}
+
+// NewKeymap returns a new empty keymap. The name is used to identify the
+// keymap, e.g. when listing the active keymaps.
+func NewKeymap(name string) *Keymap {
+	return &Keymap{name: name}
+}
+
+// Name returns the name of the keymap.
+func (m *Keymap) Name() string {
+	return m.name
+}
+
+// SetKeybinding sets the callback for when a key sequence is pressed. The
+// sequence is either a single key, e.g. "Ctrl+S", or several keys separated
+// by spaces, e.g. "Ctrl+X Ctrl+S" or "g g". Invalid and conflicting
+// keybindings are logged, and can be checked with Check.
+func (m *Keymap) SetKeybinding(seq string, fn func()) {
+	b := &keybinding{
+		sequence: seq,
+		handler:  fn,
+	}
+	if err := checkKeybinding(b, m.bindings); err != nil {
+		logger.Printf("Keybinding conflict in %s: %v", m.name, err)
+	}
+
+	m.bindings = append(m.bindings, b)
+}
+
+// Clear removes all keybindings from the keymap.
+func (m *Keymap) Clear() {
+	m.bindings = nil
+}
+
+// Keybindings returns the key sequences in the keymap, in the order they
+// were set.
+func (m *Keymap) Keybindings() []string {
+	seqs := make([]string, len(m.bindings))
+	for i, b := range m.bindings {
+		seqs[i] = b.sequence
+	}
+	return seqs
+}
+
+// Check returns an error describing the first invalid key sequence, or the
+// first pair of conflicting keybindings. Keybindings conflict if they have
+// the same key sequence, or if one sequence is the beginning of the other.
+func (m *Keymap) Check() error {
+	for i, b := range m.bindings {
+		if err := checkKeybinding(b, m.bindings[:i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keymapOwner is implemented by widgets that can have a keymap, e.g. the
+// ones embedding WidgetBase.
+type keymapOwner interface {
+	Keymap() *Keymap
+}
+
+// widgetKeymap returns the keymap of a widget, or nil if it has none.
+func widgetKeymap(w Widget) *Keymap {
+	if o, ok := w.(keymapOwner); ok {
+		return o.Keymap()
+	}
+	return nil
+}
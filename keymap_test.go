@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeymap(t *testing.T) {
+	m := NewKeymap("editor")
+	m.SetKeybinding("Ctrl+S", func() {})
+	m.SetKeybinding("Ctrl+X Ctrl+C", func() {})
+
+	if m.Name() != "editor" {
+		t.Errorf("got = %q; want = %q", m.Name(), "editor")
+	}
+
+	want := []string{"Ctrl+S", "Ctrl+X Ctrl+C"}
+	if got := m.Keybindings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v; want = %v", got, want)
+	}
+	if err := m.Check(); err != nil {
+		t.Error(err)
+	}
+
+	m.SetKeybinding("Ctrl+X", func() {})
+	if err := m.Check(); err == nil {
+		t.Errorf("expected conflict")
+	}
+
+	m.Clear()
+	if got := m.Keybindings(); len(got) != 0 {
+		t.Errorf("got = %v; want = %v", got, []string{})
+	}
+}
+
+func TestUI_ScopedKeymaps(t *testing.T) {
+	var got []string
+
+	list := NewList()
+	list.AddItems("foo", "bar")
+
+	editor := NewEntry()
+
+	listView := NewVBox(list)
+	listKeys := NewKeymap("list")
+	listKeys.SetKeybinding("d", func() { got = append(got, "delete") })
+	listKeys.SetKeybinding("q", func() { got = append(got, "close") })
+	listView.SetKeymap(listKeys)
+
+	editorView := NewVBox(editor)
+	editorKeys := NewKeymap("editor")
+	editorKeys.SetKeybinding("Ctrl+X Ctrl+S", func() { got = append(got, "save") })
+	editorView.SetKeymap(editorKeys)
+
+	root := NewHBox(listView, editorView)
+	rootKeys := NewKeymap("root")
+	rootKeys.SetKeybinding("d", func() { got = append(got, "root") })
+	root.SetKeymap(rootKeys)
+
+	ui := NewTestUI(root, 20, 2)
+	ui.SetKeybinding("q", func() { got = append(got, "quit") })
+	ui.SetKeybinding("Ctrl+X Ctrl+S", func() { got = append(got, "global save") })
+
+	chain := &SimpleFocusChain{}
+	chain.Set(list, editor)
+	ui.SetFocusChain(chain)
+
+	defer runTestUI(t, ui)()
+
+	names := func() []string {
+		var ns []string
+		ui.Update(func() {
+			for _, m := range ui.ActiveKeymaps() {
+				ns = append(ns, m.Name())
+			}
+		})
+		return ns
+	}
+
+	if want := []string{"list", "root", "global"}; !reflect.DeepEqual(names(), want) {
+		t.Errorf("got = %v; want = %v", names(), want)
+	}
+
+	// The innermost keymap shadows the outer ones.
+	if err := ui.Type("dq<Ctrl+X><Ctrl+S><Tab>"); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"editor", "root", "global"}; !reflect.DeepEqual(names(), want) {
+		t.Errorf("got = %v; want = %v", names(), want)
+	}
+
+	// The editor consumes "q", and its keymap handles the chord.
+	if err := ui.Type("q<Ctrl+X><Ctrl+S>"); err != nil {
+		t.Fatal(err)
+	}
+	ui.WaitIdle()
+
+	want := []string{"delete", "close", "global save", "save"}
+	ui.Update(func() {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %v; want = %v", got, want)
+		}
+		if editor.Text() != "q" {
+			t.Errorf("got = %q; want = %q", editor.Text(), "q")
+		}
+	})
+}
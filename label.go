@@ -24,9 +24,11 @@ type Label struct {
 
 // NewLabel returns a new Label.
 func NewLabel(text string) *Label {
-	return &Label{
+	l := &Label{
 		text: text,
 	}
+	l.trackDirtyFor(l)
+	return l
 }
 
 // Resize changes the size of the Widget.
@@ -90,16 +92,19 @@ func (l *Label) Text() string {
 
 // SetText sets the text content of the label.
 func (l *Label) SetText(text string) {
+	l.MarkDirty()
 	l.cacheSizeHint = nil
 	l.text = text
 }
 
 // SetWordWrap sets whether text content should be wrapped.
 func (l *Label) SetWordWrap(enabled bool) {
+	l.MarkDirty()
 	l.wordWrap = enabled
 }
 
 // SetStyleName sets the identifier used for custom styling.
 func (l *Label) SetStyleName(style string) {
+	l.MarkDirty()
 	l.styleName = style
 }
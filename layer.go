@@ -0,0 +1,247 @@
+package tui
+
+import "image"
+
+// Placement determines where a layer is placed on the screen.
+type Placement int
+
+// Available placements.
+const (
+	// PlaceCenter centres the layer on the screen.
+	PlaceCenter Placement = iota
+	// PlaceBelow places the layer right below the anchor widget, or above
+	// it if there isn't room below.
+	PlaceBelow
+	// PlaceAt places the top-left corner of the layer at a position on the
+	// screen, e.g. where the mouse was clicked.
+	PlaceAt
+	// PlaceRight places the layer to the right of the anchor widget, or to
+	// the left of it if there isn't room to the right.
+	PlaceRight
+	// PlaceCursor places the layer below the text cursor, e.g. for
+	// completions. The layer is centred if no cursor is shown.
+	PlaceCursor
+)
+
+// LayerOptions determine how a layer is placed and drawn. The zero value
+// centres the layer on the screen.
+type LayerOptions struct {
+	Placement Placement
+
+	// Anchor is the widget the layer is placed next to with PlaceBelow and
+	// PlaceRight.
+	Anchor Widget
+
+	// Pos is the position on the screen used with PlaceAt.
+	Pos image.Point
+
+	// Offset moves the layer from where it's placed, e.g. to line up a
+	// submenu with the item that opened it.
+	Offset image.Point
+
+	// Size is the size of the layer. If zero, the size hint of the widget is
+	// used. The layer is moved and shrunk to fit on the screen.
+	Size image.Point
+
+	// Dim dims everything below the layer, using the "layer.dim" style.
+	Dim bool
+
+	// FocusChain determines the focus order within the layer. If nil, the
+	// focus moves between the focusable widgets of the layer in the order
+	// they are laid out.
+	FocusChain FocusChain
+
+	// OnClickOutside is called when a mouse button is pressed outside the
+	// layer while it's on top, e.g. to close a popup.
+	OnClickOutside func()
+
+	// OnClickBelow is called instead of OnClickOutside if set. It's given
+	// the widget of the topmost layer under the pointer, or nil if there is
+	// none. If it returns true, the press is then delivered to the layer on
+	// top by then, e.g. to let a menu close its submenus and handle the
+	// press itself.
+	OnClickBelow func(below Widget) bool
+
+	// OnRemove is called once the layer has been removed, whether by
+	// PopLayer or RemoveLayer.
+	OnRemove func()
+}
+
+// layer is a widget drawn on top of the root widget.
+type layer struct {
+	widget Widget
+	opts   LayerOptions
+
+	// bounds is the area occupied by the layer on the screen.
+	bounds image.Rectangle
+
+	// prevChain and prevFocus are the focus state below the layer, which
+	// is restored when the layer is removed.
+	prevChain FocusChain
+	prevFocus Widget
+}
+
+// layerStack draws the root widget, with the layers on top of it.
+type layerStack struct {
+	WidgetBase
+
+	root   Widget
+	layers []*layer
+
+	// cursor is where the text cursor was drawn in the previous frame, if
+	// hasCursor is set. It's used to place layers with PlaceCursor.
+	cursor    image.Point
+	hasCursor bool
+}
+
+// top returns the topmost layer, or nil if there are none.
+func (s *layerStack) top() *layer {
+	if len(s.layers) == 0 {
+		return nil
+	}
+	return s.layers[len(s.layers)-1]
+}
+
+// layerAt returns the widget of the topmost layer below the top one that
+// covers the given point on the screen, or nil if there is none.
+func (s *layerStack) layerAt(pt image.Point) Widget {
+	for i := len(s.layers) - 2; i >= 0; i-- {
+		if pt.In(s.layers[i].bounds) {
+			return s.layers[i].widget
+		}
+	}
+	return nil
+}
+
+// inputRoot returns the widget that receives input, which is the topmost
+// layer if there is one, and the position of its top-left corner on the
+// screen.
+func (s *layerStack) inputRoot() (Widget, image.Point) {
+	if l := s.top(); l != nil {
+		return l.widget, l.bounds.Min
+	}
+	return s.root, image.Point{}
+}
+
+// Draw draws the root widget and the layers.
+func (s *layerStack) Draw(p *Painter) {
+	p.DrawWidget(s.root)
+
+	for _, l := range s.layers {
+		if l.opts.Dim {
+			p.restyle(p.theme.Style("layer.dim"))
+		}
+
+		p.Translate(l.bounds.Min.X, l.bounds.Min.Y)
+		p.WithMask(image.Rectangle{Max: l.bounds.Size()}, func(p *Painter) {
+			p.WithStyle("layer", func(p *Painter) {
+				p.FillRect(0, 0, l.bounds.Dx(), l.bounds.Dy())
+				p.DrawWidget(l.widget)
+			})
+		})
+		p.Restore()
+	}
+
+	s.cursor, s.hasCursor = p.cursor, p.hasCursor
+}
+
+// Resize lays out the root widget on the whole screen, and then places the
+// layers from the bottom up.
+func (s *layerStack) Resize(size image.Point) {
+	s.WidgetBase.Resize(size)
+	s.root.Resize(size)
+
+	for _, l := range s.layers {
+		l.bounds = s.place(l, size)
+		l.widget.Resize(l.bounds.Size())
+	}
+}
+
+// place returns the area occupied by a layer on a screen of the given size.
+func (s *layerStack) place(l *layer, screen image.Point) image.Rectangle {
+	size := l.opts.Size
+	if size == (image.Point{}) {
+		size = l.widget.SizeHint()
+		minSize := l.widget.MinSizeHint()
+		size.X = maxOf(size.X, minSize.X)
+		size.Y = maxOf(size.Y, minSize.Y)
+	}
+	size.X = minOf(size.X, screen.X)
+	size.Y = minOf(size.Y, screen.Y)
+
+	pos := screen.Sub(size).Div(2)
+
+	switch l.opts.Placement {
+	case PlaceBelow:
+		if anchor, ok := s.bounds(l.opts.Anchor); ok {
+			pos = placeBelow(anchor, size, screen)
+		}
+	case PlaceRight:
+		if anchor, ok := s.bounds(l.opts.Anchor); ok {
+			pos = image.Pt(anchor.Max.X, anchor.Min.Y)
+			if pos.X+size.X > screen.X && anchor.Min.X-size.X >= 0 {
+				pos.X = anchor.Min.X - size.X
+			}
+		}
+	case PlaceAt:
+		pos = l.opts.Pos
+	case PlaceCursor:
+		if s.hasCursor {
+			pos = placeBelow(image.Rectangle{Min: s.cursor, Max: s.cursor.Add(image.Pt(1, 1))}, size, screen)
+		}
+	}
+	pos = pos.Add(l.opts.Offset)
+
+	pos.X = maxOf(0, minOf(pos.X, screen.X-size.X))
+	pos.Y = maxOf(0, minOf(pos.Y, screen.Y-size.Y))
+
+	return image.Rectangle{Min: pos, Max: pos.Add(size)}
+}
+
+// placeBelow returns the position of a layer right below the given area, or
+// above it if there isn't room below.
+func placeBelow(anchor image.Rectangle, size, screen image.Point) image.Point {
+	pos := image.Pt(anchor.Min.X, anchor.Max.Y)
+	if pos.Y+size.Y > screen.Y && anchor.Min.Y-size.Y >= 0 {
+		pos.Y = anchor.Min.Y - size.Y
+	}
+	return pos
+}
+
+// bounds returns the area occupied by a widget on the screen.
+func (s *layerStack) bounds(target Widget) (image.Rectangle, bool) {
+	var (
+		r     = rootBounds(s)
+		area  image.Rectangle
+		found bool
+	)
+	walkWidgets(s, r, r, func(w Widget, bounds, _ image.Rectangle) {
+		if !found && w == target {
+			area, found = bounds, true
+		}
+	})
+	return area, found
+}
+
+// Children returns the root widget followed by the layers, from the bottom
+// up.
+func (s *layerStack) Children() []Widget {
+	ws := []Widget{s.root}
+	for _, l := range s.layers {
+		ws = append(ws, l.widget)
+	}
+	return ws
+}
+
+// ChildBounds returns the area occupied by the root widget or a layer.
+func (s *layerStack) ChildBounds(w Widget) image.Rectangle {
+	if w == s.root {
+		return image.Rectangle{Max: s.Size()}
+	}
+	for _, l := range s.layers {
+		if l.widget == w {
+			return l.bounds
+		}
+	}
+	return image.Rectangle{}
+}
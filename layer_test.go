@@ -0,0 +1,173 @@
+package tui
+
+import (
+	"image"
+	"testing"
+)
+
+func TestLayerStack_Place(t *testing.T) {
+	top := NewLabel("top")
+	bottom := NewLabel("bottom")
+	root := NewVBox(NewPadder(2, 1, top), NewSpacer(), NewPadder(2, 0, bottom))
+
+	screen := image.Pt(20, 9)
+
+	for _, tt := range []struct {
+		test string
+		opts LayerOptions
+		hint image.Point
+		want image.Rectangle
+	}{
+		{"center", LayerOptions{}, image.Pt(6, 3), image.Rect(7, 3, 13, 6)},
+		{"explicit size", LayerOptions{Size: image.Pt(4, 1)}, image.Pt(6, 3), image.Rect(8, 4, 12, 5)},
+		{"larger than screen", LayerOptions{}, image.Pt(30, 3), image.Rect(0, 3, 20, 6)},
+		{"below", LayerOptions{Placement: PlaceBelow, Anchor: top}, image.Pt(6, 3), image.Rect(2, 2, 8, 5)},
+		{"above", LayerOptions{Placement: PlaceBelow, Anchor: bottom}, image.Pt(6, 3), image.Rect(2, 5, 8, 8)},
+		{"no room above or below", LayerOptions{Placement: PlaceBelow, Anchor: top}, image.Pt(6, 8), image.Rect(2, 1, 8, 9)},
+		{"missing anchor", LayerOptions{Placement: PlaceBelow, Anchor: NewLabel("")}, image.Pt(6, 3), image.Rect(7, 3, 13, 6)},
+		{"at", LayerOptions{Placement: PlaceAt, Pos: image.Pt(3, 2)}, image.Pt(6, 3), image.Rect(3, 2, 9, 5)},
+		{"at edge", LayerOptions{Placement: PlaceAt, Pos: image.Pt(18, 8)}, image.Pt(6, 3), image.Rect(14, 6, 20, 9)},
+		{"offset", LayerOptions{Offset: image.Pt(1, -1)}, image.Pt(6, 3), image.Rect(8, 2, 14, 5)},
+		{"right", LayerOptions{Placement: PlaceRight, Anchor: top, Offset: image.Pt(0, 1)}, image.Pt(2, 3), image.Rect(18, 2, 20, 5)},
+		{"no cursor", LayerOptions{Placement: PlaceCursor}, image.Pt(6, 3), image.Rect(7, 3, 13, 6)},
+	} {
+		s := &layerStack{root: root}
+		l := &layer{widget: &sizedWidget{hint: tt.hint}, opts: tt.opts}
+		s.layers = []*layer{l}
+		s.Resize(screen)
+
+		if l.bounds != tt.want {
+			t.Errorf("%s: got = %v; want = %v", tt.test, l.bounds, tt.want)
+		}
+	}
+}
+
+// sizedWidget is a widget with a fixed size hint.
+type sizedWidget struct {
+	WidgetBase
+
+	hint image.Point
+}
+
+func (w *sizedWidget) SizeHint() image.Point {
+	return w.hint
+}
+
+func TestUI_PushLayer(t *testing.T) {
+	a := NewEntry()
+	b := NewEntry()
+	root := NewVBox(a, b, NewLabel("background"))
+
+	chain := &SimpleFocusChain{}
+	chain.Set(a, b)
+
+	c := NewEntry()
+	d := NewEntry()
+	dialog := NewVBox(c, d)
+	dialog.SetBorder(true)
+
+	ui := NewTestUI(root, 12, 5)
+	ui.SetFocusChain(chain)
+	defer runTestUI(t, ui)()
+
+	if err := ui.Type("a<Tab>"); err != nil {
+		t.Fatal(err)
+	}
+
+	ui.Update(func() {
+		ui.PushLayer(dialog, LayerOptions{Size: image.Pt(8, 4), Dim: true})
+	})
+
+	// Tab and clicks don't leave the layer.
+	if err := ui.Type("c<Tab>d<Tab>c"); err != nil {
+		t.Fatal(err)
+	}
+	ui.Click(0, 0)
+	ui.Click(3, 2)
+	if err := ui.Type("d"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+a ┌──────┐  
+  │cc    │  
+  │dd    │  
+  └──────┘  
+background  
+`
+	surface := ui.Snapshot()
+	if diff := surfaceEquals(surface, want); diff != "" {
+		t.Error(diff)
+	}
+
+	// Everything below the layer is dimmed.
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 12; x++ {
+			_, style, _ := surface.Cell(x, y)
+			below := x < 2 || x >= 10 || y >= 4
+			if got := style.Dim == DecorationOn; got != below {
+				t.Errorf("(%d, %d): dim = %v; want = %v", x, y, got, below)
+			}
+		}
+	}
+
+	// The focus returns to where it was when the layer was pushed.
+	ui.Update(func() {
+		ui.PopLayer()
+	})
+	if err := ui.Type("b"); err != nil {
+		t.Fatal(err)
+	}
+
+	want = `
+a           
+            
+b           
+            
+background  
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestUI_RemoveLayer(t *testing.T) {
+	a := NewEntry()
+	b := NewEntry()
+	c := NewEntry()
+	root := NewVBox(a)
+
+	ui := NewTestUI(root, 10, 3)
+	ui.SetFocusChain(NewTreeFocusChain(root))
+	defer runTestUI(t, ui)()
+
+	// Removing a layer below the top one leaves the top one, and its
+	// focus, alone.
+	ui.Update(func() {
+		ui.PushLayer(b, LayerOptions{})
+		ui.PushLayer(c, LayerOptions{})
+		ui.RemoveLayer(b)
+	})
+	if err := ui.Type("c"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the top layer is removed, the focus goes back to where it was
+	// before either layer was pushed.
+	ui.Update(func() {
+		ui.PopLayer()
+	})
+	if err := ui.Type("a"); err != nil {
+		t.Fatal(err)
+	}
+	ui.WaitIdle()
+
+	ui.Update(func() {
+		if got := a.Text() + b.Text() + c.Text(); got != "ac" {
+			t.Errorf("got = %q; want = %q", got, "ac")
+		}
+		if len(ui.layers.layers) != 0 {
+			t.Errorf("got %d layers; want none", len(ui.layers.layers))
+		}
+	})
+}
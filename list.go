@@ -3,6 +3,8 @@ package tui
 import "image"
 
 var _ Widget = &List{}
+var _ Focusable = &List{}
+var _ MouseHandler = &List{}
 
 // List is a widget for displaying and selecting items.
 type List struct {
@@ -18,9 +20,11 @@ type List struct {
 
 // NewList returns a new List with no selection.
 func NewList() *List {
-	return &List{
+	l := &List{
 		selected: -1,
 	}
+	l.trackDirtyFor(l)
+	return l
 }
 
 // Draw draws the list.
@@ -48,32 +52,74 @@ func (l *List) SizeHint() image.Point {
 	return image.Point{width, len(l.items)}
 }
 
+// Focusable returns whether the list can be focused.
+func (l *List) Focusable() bool {
+	return l.IsEnabled()
+}
+
 // OnKeyEvent handles terminal events.
 func (l *List) OnKeyEvent(ev KeyEvent) {
 	if !l.IsFocused() {
 		return
 	}
 
+	// Leave keys with modifiers to the keybindings.
+	if ev.Modifiers != ModNone {
+		return
+	}
+
 	switch ev.Key {
 	case KeyUp:
 		l.moveUp()
+		ev.Consume()
 	case KeyDown:
 		l.moveDown()
+		ev.Consume()
 	case KeyEnter:
 		if l.onItemActivated != nil {
 			l.onItemActivated(l)
+			ev.Consume()
+		}
+	case KeyRune:
+		switch ev.Rune {
+		case 'k':
+			l.moveUp()
+			ev.Consume()
+		case 'j':
+			l.moveDown()
+			ev.Consume()
 		}
 	}
+}
 
-	switch ev.Rune {
-	case 'k':
-		l.moveUp()
-	case 'j':
-		l.moveDown()
+// OnMouseEvent selects the item under the pointer when clicked, and moves the
+// selection when the mouse wheel is used.
+func (l *List) OnMouseEvent(ev MouseEvent) {
+	if !l.IsEnabled() {
+		return
+	}
+	switch ev.Action {
+	case MousePress:
+		if ev.Button != MouseButtonLeft {
+			return
+		}
+		i := ev.Pos.Y + l.pos
+		if i < 0 || i >= len(l.items) {
+			return
+		}
+		l.Select(i)
+	case MouseWheel:
+		switch ev.Wheel {
+		case WheelUp:
+			l.moveUp()
+		case WheelDown:
+			l.moveDown()
+		}
 	}
 }
 
 func (l *List) moveUp() {
+	l.MarkDirty()
 	if l.selected > 0 {
 		l.selected--
 
@@ -87,6 +133,7 @@ func (l *List) moveUp() {
 }
 
 func (l *List) moveDown() {
+	l.MarkDirty()
 	if l.selected < len(l.items)-1 {
 		l.selected++
 		if l.selected >= l.pos+len(l.items) {
@@ -100,11 +147,13 @@ func (l *List) moveDown() {
 
 // AddItems appends items to the end of the list.
 func (l *List) AddItems(items ...string) {
+	l.MarkDirty()
 	l.items = append(l.items, items...)
 }
 
 // RemoveItems clears all the items from the list.
 func (l *List) RemoveItems() {
+	l.MarkDirty()
 	l.items = []string{}
 	l.pos = 0
 	l.selected = -1
@@ -115,6 +164,7 @@ func (l *List) RemoveItems() {
 
 // RemoveItem removes the item at the given position.
 func (l *List) RemoveItem(i int) {
+	l.MarkDirty()
 	// Adjust pos and selected before removing.
 	if l.pos >= len(l.items) {
 		l.pos--
@@ -144,6 +194,7 @@ func (l *List) Length() int {
 
 // SetSelected sets the currently selected item.
 func (l *List) SetSelected(i int) {
+	l.MarkDirty()
 	l.selected = i
 }
 
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"image"
 	"testing"
 )
 
@@ -75,3 +76,61 @@ four
 		t.Errorf("got = %d; want = %d", l.Selected(), 1)
 	}
 }
+
+func TestList_OnMouseEvent(t *testing.T) {
+	l := NewList()
+	l.AddItems("one", "two", "three")
+	l.Resize(image.Point{5, 3})
+
+	var changed int
+	l.OnSelectionChanged(func(*List) {
+		changed++
+	})
+
+	l.OnMouseEvent(MouseEvent{Pos: image.Pt(2, 1), Action: MousePress, Button: MouseButtonLeft})
+	if l.Selected() != 1 {
+		t.Errorf("got = %d; want = %d", l.Selected(), 1)
+	}
+
+	l.OnMouseEvent(MouseEvent{Pos: image.Pt(2, 5), Action: MousePress, Button: MouseButtonLeft})
+	if l.Selected() != 1 {
+		t.Errorf("got = %d; want = %d", l.Selected(), 1)
+	}
+
+	l.OnMouseEvent(MouseEvent{Action: MouseWheel, Wheel: WheelDown})
+	if l.Selected() != 2 {
+		t.Errorf("got = %d; want = %d", l.Selected(), 2)
+	}
+
+	if changed != 2 {
+		t.Errorf("got = %d; want = %d", changed, 2)
+	}
+}
+
+func TestList_OnKeyEvent_Consume(t *testing.T) {
+	for _, tt := range []struct {
+		event    KeyEvent
+		consumed bool
+	}{
+		{KeyEvent{Key: KeyDown}, true},
+		{KeyEvent{Key: KeyRune, Rune: 'k'}, true},
+		{KeyEvent{Key: KeyRune, Rune: 'q'}, false},
+		{KeyEvent{Key: KeyUp, Modifiers: ModShift | ModAlt}, false},
+		{KeyEvent{Key: KeyEnter}, false},
+	} {
+		tt := tt
+		t.Run(tt.event.Name(), func(t *testing.T) {
+			l := NewList()
+			l.AddItems("foo", "bar")
+			l.SetFocused(true)
+
+			ev := tt.event
+			ev.state = &eventState{}
+			l.OnKeyEvent(ev)
+
+			if ev.Consumed() != tt.consumed {
+				t.Errorf("got = %v; want = %v", ev.Consumed(), tt.consumed)
+			}
+		})
+	}
+}
@@ -3,3 +3,19 @@ package tui
 // Maximum integer value for the current architecture.
 const maxUint = ^uint(0)
 const maxInt = int(maxUint >> 1)
+
+// minOf returns the smaller of a and b.
+func minOf(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxOf returns the larger of a and b.
+func maxOf(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
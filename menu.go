@@ -0,0 +1,417 @@
+package tui
+
+import "image"
+
+var _ Widget = &Menu{}
+var _ Focusable = &Menu{}
+var _ MouseHandler = &Menu{}
+
+// MenuItem is an item in a Menu. Items are activated with Enter or by
+// clicking them.
+type MenuItem struct {
+	// menu is the menu the item is in.
+	menu *Menu
+
+	text     string
+	shortcut string
+
+	disabled  bool
+	separator bool
+
+	checkable bool
+	checked   bool
+
+	submenu *Menu
+
+	onActivated func(*MenuItem)
+}
+
+// Text returns the text of the item.
+func (i *MenuItem) Text() string {
+	return i.text
+}
+
+// SetShortcut sets a hint shown next to the item, e.g. "Ctrl+S", for a
+// keybinding that does the same thing. The keybinding itself is set with
+// SetKeybinding. Items with a submenu show an arrow instead.
+func (i *MenuItem) SetShortcut(s string) {
+	i.menu.MarkDirty()
+	i.shortcut = s
+}
+
+// SetEnabled sets whether the item can be activated.
+func (i *MenuItem) SetEnabled(enabled bool) {
+	i.menu.MarkDirty()
+	i.disabled = !enabled
+}
+
+// IsEnabled returns whether the item can be activated.
+func (i *MenuItem) IsEnabled() bool {
+	return !i.disabled && !i.separator
+}
+
+// SetCheckable sets whether the item shows a check mark when checked. A
+// checkable item is checked or unchecked whenever it's activated.
+func (i *MenuItem) SetCheckable(checkable bool) {
+	i.menu.MarkDirty()
+	i.checkable = checkable
+}
+
+// SetChecked sets whether a checkable item is checked.
+func (i *MenuItem) SetChecked(checked bool) {
+	i.menu.MarkDirty()
+	i.checked = checked
+}
+
+// IsChecked returns whether the item is checked.
+func (i *MenuItem) IsChecked() bool {
+	return i.checked
+}
+
+// Submenu returns the menu opened by the item, or nil if it has none.
+func (i *MenuItem) Submenu() *Menu {
+	return i.submenu
+}
+
+// OnActivated sets a function to be run whenever the item is activated.
+func (i *MenuItem) OnActivated(fn func(*MenuItem)) {
+	i.onActivated = fn
+}
+
+// Menu is a popup menu. It's shown on top of the other widgets with Show,
+// and closes once an item has been activated, or when Esc is pressed.
+type Menu struct {
+	WidgetBase
+
+	items    []*MenuItem
+	selected int
+
+	// ui is the UI the menu is shown in, or nil if it's closed.
+	ui UI
+
+	// parent is the menu that opened this one as a submenu, and child the
+	// submenu this one has open.
+	parent, child *Menu
+}
+
+// NewMenu returns a new empty Menu.
+func NewMenu() *Menu {
+	m := &Menu{
+		selected: -1,
+	}
+	m.trackDirtyFor(m)
+	return m
+}
+
+// AddItem adds an item with the given text at the end of the menu.
+func (m *Menu) AddItem(text string) *MenuItem {
+	m.MarkDirty()
+	item := &MenuItem{menu: m, text: text}
+	m.items = append(m.items, item)
+	return item
+}
+
+// AddSubmenu adds an item that opens another menu.
+func (m *Menu) AddSubmenu(text string, sub *Menu) *MenuItem {
+	item := m.AddItem(text)
+	item.submenu = sub
+	return item
+}
+
+// AddSeparator adds a line between two groups of items.
+func (m *Menu) AddSeparator() {
+	m.MarkDirty()
+	m.items = append(m.items, &MenuItem{menu: m, separator: true})
+}
+
+// Items returns the items of the menu, including the separators.
+func (m *Menu) Items() []*MenuItem {
+	return m.items
+}
+
+// Selected returns the selected item, or nil if no item is selected.
+func (m *Menu) Selected() *MenuItem {
+	if m.selected < 0 || m.selected >= len(m.items) {
+		return nil
+	}
+	return m.items[m.selected]
+}
+
+// Show opens the menu as a layer on top of the other widgets. Use the
+// placement of the options to open it below a widget, at the text cursor or
+// where the mouse was clicked. The menu is closed when the mouse is pressed
+// outside of it.
+func (m *Menu) Show(ui UI, opts LayerOptions) {
+	if m.ui != nil {
+		return
+	}
+	m.ui = ui
+	m.MarkDirty()
+	m.selected = -1
+	m.selectNext(1)
+
+	opts.OnClickBelow = m.clickBelow
+	onRemove := opts.OnRemove
+	opts.OnRemove = func() {
+		m.removed()
+		if onRemove != nil {
+			onRemove()
+		}
+	}
+	ui.PushLayer(m, opts)
+}
+
+// Close closes the menu, along with any submenu it has open.
+func (m *Menu) Close() {
+	if m.ui == nil {
+		return
+	}
+	m.ui.RemoveLayer(m)
+}
+
+// IsOpen returns whether the menu is shown.
+func (m *Menu) IsOpen() bool {
+	return m.ui != nil
+}
+
+// removed resets the menu once its layer has been removed, whether by Close
+// or by anything else, and closes its submenu.
+func (m *Menu) removed() {
+	m.ui = nil
+	if m.child != nil {
+		m.child.Close()
+	}
+	if m.parent != nil {
+		m.parent.child = nil
+		m.parent = nil
+	}
+}
+
+// clickBelow handles a press outside the menu. Pressing one of the menus
+// that opened it closes the submenus above that menu, and lets it handle the
+// press. Pressing anywhere else closes all the menus.
+func (m *Menu) clickBelow(below Widget) bool {
+	for p := m.parent; p != nil; p = p.parent {
+		if below == Widget(p) {
+			p.child.Close()
+			return true
+		}
+	}
+	m.closeAll()
+	return false
+}
+
+// closeAll closes the menu and the menus that opened it.
+func (m *Menu) closeAll() {
+	root := m
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.Close()
+}
+
+// openSubmenu opens the submenu of the selected item next to the menu, lined
+// up with the item.
+func (m *Menu) openSubmenu() bool {
+	item := m.Selected()
+	if item == nil || item.submenu == nil || !item.IsEnabled() || m.ui == nil {
+		return false
+	}
+	sub := item.submenu
+	m.child = sub
+	sub.parent = m
+	sub.Show(m.ui, LayerOptions{
+		Placement: PlaceRight,
+		Anchor:    m,
+		Offset:    image.Pt(0, m.selected),
+	})
+	return true
+}
+
+// activate activates the selected item. Items with a submenu open it, while
+// other items close the menus before running their callback.
+func (m *Menu) activate() {
+	item := m.Selected()
+	if item == nil || !item.IsEnabled() {
+		return
+	}
+	if m.openSubmenu() {
+		return
+	}
+	if item.checkable {
+		item.SetChecked(!item.checked)
+	}
+	m.closeAll()
+	if item.onActivated != nil {
+		item.onActivated(item)
+	}
+}
+
+// selectNext moves the selection in the given direction to the next item
+// that can be activated, wrapping around at the ends.
+func (m *Menu) selectNext(dir int) {
+	n := len(m.items)
+	i := m.selected
+	if i < 0 && dir < 0 {
+		i = n
+	}
+	for k := 0; k < n; k++ {
+		i = ((i+dir)%n + n) % n
+		if m.items[i].IsEnabled() {
+			m.MarkDirty()
+			m.selected = i
+			return
+		}
+	}
+}
+
+// Focusable returns true, so that the menu gets the focus when shown.
+func (m *Menu) Focusable() bool {
+	return true
+}
+
+// OnKeyEvent moves the selection with the arrow keys, activates the selected
+// item with Enter and closes the menu with Esc. Right opens a submenu and Left
+// closes it.
+func (m *Menu) OnKeyEvent(ev KeyEvent) {
+	if !m.IsFocused() || ev.Modifiers != ModNone {
+		return
+	}
+
+	switch ev.Key {
+	case KeyUp:
+		m.selectNext(-1)
+	case KeyDown:
+		m.selectNext(1)
+	case KeyEnter:
+		m.activate()
+	case KeyRight:
+		m.openSubmenu()
+	case KeyLeft:
+		if m.parent == nil {
+			return
+		}
+		m.Close()
+	case KeyEsc:
+		m.Close()
+	default:
+		return
+	}
+	ev.Consume()
+}
+
+// OnMouseEvent selects the item under the pointer, and activates it when
+// clicked.
+func (m *Menu) OnMouseEvent(ev MouseEvent) {
+	i := ev.Pos.Y - 1
+	if i < 0 || i >= len(m.items) || ev.Pos.X <= 0 || ev.Pos.X >= m.Size().X-1 {
+		return
+	}
+	if !m.items[i].IsEnabled() {
+		return
+	}
+
+	switch ev.Action {
+	case MouseMove, MousePress, MouseDrag:
+		if m.selected != i {
+			m.MarkDirty()
+			m.selected = i
+		}
+	case MouseRelease:
+		if ev.Button == MouseButtonLeft {
+			m.selected = i
+			m.activate()
+		}
+	}
+}
+
+// hint returns what's shown to the right of an item: an arrow for items
+// with a submenu, and the shortcut for the others.
+func (i *MenuItem) hint() string {
+	if i.submenu != nil {
+		return "▸"
+	}
+	return i.shortcut
+}
+
+// columns returns the width of the check mark column, the text column and the
+// hint column.
+func (m *Menu) columns() (check, text, hint int) {
+	for _, item := range m.items {
+		if item.checkable {
+			check = 2
+		}
+		text = maxOf(text, stringWidth(item.text))
+		if h := item.hint(); h != "" {
+			hint = maxOf(hint, stringWidth(h)+2)
+		}
+	}
+	return check, text, hint
+}
+
+// SizeHint returns the size needed to show all items.
+func (m *Menu) SizeHint() image.Point {
+	check, text, hint := m.columns()
+	return image.Point{check + text + hint + 4, len(m.items) + 2}
+}
+
+// Draw draws the menu with a border around it.
+func (m *Menu) Draw(p *Painter) {
+	s := m.Size()
+	check, _, _ := m.columns()
+
+	p.WithStyle("menu", func(p *Painter) {
+		p.FillRect(0, 0, s.X, s.Y)
+		p.WithStyle("menu.border", func(p *Painter) {
+			p.DrawRect(0, 0, s.X, s.Y)
+		})
+
+		for i, item := range m.items {
+			y := i + 1
+
+			if item.separator {
+				p.WithStyle("menu.border", func(p *Painter) {
+					p.DrawRune(0, y, '├')
+					p.DrawHorizontalLine(1, s.X-1, y)
+					p.DrawRune(s.X-1, y, '┤')
+				})
+				continue
+			}
+
+			style := "menu.item"
+			switch {
+			case !item.IsEnabled():
+				style += ".disabled"
+			case i == m.selected:
+				style += ".selected"
+			}
+
+			p.WithStyle(style, func(p *Painter) {
+				p.WithMask(image.Rect(1, y, s.X-1, y+1), func(p *Painter) {
+					p.FillRect(1, y, s.X-2, 1)
+					if item.checkable && item.checked {
+						p.DrawRune(2, y, '✓')
+					}
+					p.DrawText(2+check, y, item.text)
+
+					hint := item.hint()
+					p.DrawText(s.X-2-stringWidth(hint), y, hint)
+				})
+			})
+		}
+	})
+}
+
+// contextMenuOwner is implemented by widgets that may have a context menu.
+type contextMenuOwner interface {
+	ContextMenu() *Menu
+}
+
+// widgetContextMenu returns the context menu of a widget, or nil if it has
+// none.
+func widgetContextMenu(w Widget) *Menu {
+	if o, ok := w.(contextMenuOwner); ok {
+		return o.ContextMenu()
+	}
+	return nil
+}
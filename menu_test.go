@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"image"
+	"testing"
+)
+
+func newTestMenu(got *string) (*Menu, *Menu) {
+	record := func(item *MenuItem) {
+		*got = item.Text()
+	}
+
+	recent := NewMenu()
+	recent.AddItem("a.txt").OnActivated(record)
+	recent.AddItem("b.txt").OnActivated(record)
+
+	m := NewMenu()
+	open := m.AddItem("Open")
+	open.SetShortcut("Ctrl+O")
+	open.OnActivated(record)
+	m.AddSubmenu("Recent", recent)
+	m.AddSeparator()
+	wrap := m.AddItem("Wrap")
+	wrap.SetCheckable(true)
+	wrap.SetChecked(true)
+	wrap.OnActivated(record)
+	m.AddItem("Close").SetEnabled(false)
+
+	return m, recent
+}
+
+func TestMenu_Draw(t *testing.T) {
+	var got string
+	m, _ := newTestMenu(&got)
+
+	surface := NewTestSurface(20, 8)
+	painter := NewPainter(surface, NewTheme())
+	painter.Repaint(NewVBox(m, NewSpacer()))
+
+	want := `
+┌──────────────────┐
+│   Open    Ctrl+O │
+│   Recent       ▸ │
+├──────────────────┤
+│ ✓ Wrap           │
+│   Close          │
+└──────────────────┘
+                    
+`
+	if diff := surfaceEquals(surface, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestUI_Menu(t *testing.T) {
+	var got string
+	m, recent := newTestMenu(&got)
+
+	btn := NewButton("File")
+	entry := NewEntry()
+	root := NewVBox(NewHBox(btn, NewSpacer()), entry, NewSpacer())
+
+	ui := NewTestUI(root, 32, 10)
+	ui.SetFocusChain(NewTreeFocusChain(root))
+	btn.OnActivated(func(*Button) {
+		m.Show(ui, LayerOptions{Placement: PlaceBelow, Anchor: btn})
+	})
+	defer runTestUI(t, ui)()
+
+	for _, tt := range []struct {
+		keys string
+		want string
+	}{
+		// Separators and disabled items are skipped.
+		{"<Enter><Down><Down><Enter>", "Wrap"},
+		{"<Enter><Up><Enter>", "Wrap"},
+		// Submenus open to the right, and close with Left.
+		{"<Enter><Down><Right><Left><Down><Down><Enter>", "Open"},
+		{"<Enter><Down><Enter><Down><Enter>", "b.txt"},
+	} {
+		got = ""
+		if err := ui.Type(tt.keys); err != nil {
+			t.Fatal(err)
+		}
+		ui.WaitIdle()
+
+		ui.Update(func() {
+			if got != tt.want {
+				t.Errorf("%s: got = %q; want = %q", tt.keys, got, tt.want)
+			}
+			if m.IsOpen() || recent.IsOpen() {
+				t.Errorf("%s: expected menus to be closed", tt.keys)
+			}
+		})
+	}
+
+	if !m.Items()[3].IsChecked() {
+		t.Errorf("expected item to be checked after activating it twice")
+	}
+
+	if err := ui.Type("<Enter><Down><Right>"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+File                            
+┌──────────────────┐            
+│   Open    Ctrl+O │┌───────┐   
+│   Recent       ▸ ││ a.txt │   
+├──────────────────┤│ b.txt │   
+│ ✓ Wrap           │└───────┘   
+│   Close          │            
+└──────────────────┘            
+                                
+                                
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+
+	// Esc closes the submenu, and then the menu, and the focus returns to
+	// the button.
+	if err := ui.Type("<Esc><Esc>x"); err != nil {
+		t.Fatal(err)
+	}
+	ui.WaitIdle()
+
+	ui.Update(func() {
+		if m.IsOpen() || recent.IsOpen() {
+			t.Errorf("expected menus to be closed")
+		}
+		if !btn.IsFocused() {
+			t.Errorf("expected button to be focused")
+		}
+	})
+
+	// Clicking the menu while the submenu is open closes the submenu only,
+	// and the click then activates the item of the menu.
+	got = ""
+	if err := ui.Type("<Enter><Down><Right>"); err != nil {
+		t.Fatal(err)
+	}
+	ui.Click(5, 2)
+	ui.WaitIdle()
+
+	ui.Update(func() {
+		if got != "Open" {
+			t.Errorf("got = %q; want = %q", got, "Open")
+		}
+		if m.IsOpen() || recent.IsOpen() {
+			t.Errorf("expected menus to be closed")
+		}
+	})
+}
+
+func TestUI_ContextMenu(t *testing.T) {
+	var got string
+	m, _ := newTestMenu(&got)
+
+	label := NewLabel("right-click me")
+	root := NewVBox(label, NewSpacer())
+	label.SetContextMenu(m)
+
+	ui := NewTestUI(root, 24, 8)
+	defer runTestUI(t, ui)()
+
+	ui.SendMouse(MouseEvent{Pos: image.Pt(2, 0), Action: MousePress, Button: MouseButtonRight})
+	ui.SendMouse(MouseEvent{Pos: image.Pt(2, 0), Action: MouseRelease, Button: MouseButtonRight})
+
+	want := `
+ri┌──────────────────┐  
+  │   Open    Ctrl+O │  
+  │   Recent       ▸ │  
+  ├──────────────────┤  
+  │ ✓ Wrap           │  
+  │   Close          │  
+  └──────────────────┘  
+                        
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+
+	// Clicking outside closes the menu.
+	ui.Click(0, 7)
+	ui.WaitIdle()
+	ui.Update(func() {
+		if m.IsOpen() {
+			t.Errorf("expected menu to be closed")
+		}
+	})
+
+	ui.SendMouse(MouseEvent{Pos: image.Pt(2, 0), Action: MousePress, Button: MouseButtonRight})
+	ui.Click(6, 1)
+	ui.WaitIdle()
+
+	ui.Update(func() {
+		if got != "Open" {
+			t.Errorf("got = %q; want = %q", got, "Open")
+		}
+		if m.IsOpen() {
+			t.Errorf("expected menu to be closed")
+		}
+	})
+}
+
+func TestUI_MenuCloseBelowLayer(t *testing.T) {
+	var got string
+	m, _ := newTestMenu(&got)
+	dialog := NewLabel("dialog")
+
+	ui := NewTestUI(NewSpacer(), 20, 8)
+	defer runTestUI(t, ui)()
+
+	// Closing the menu leaves the dialog opened on top of it.
+	ui.Update(func() {
+		m.Show(ui, LayerOptions{})
+		ui.PushLayer(dialog, LayerOptions{})
+		m.Close()
+
+		if m.IsOpen() {
+			t.Errorf("expected menu to be closed")
+		}
+		if l := ui.layers.top(); l == nil || l.widget != dialog {
+			t.Errorf("expected dialog to be on top")
+		}
+		if len(ui.layers.layers) != 1 {
+			t.Errorf("got %d layers; want 1", len(ui.layers.layers))
+		}
+	})
+}
+
+func TestUI_MenuPopLayer(t *testing.T) {
+	var got string
+	m, recent := newTestMenu(&got)
+
+	ui := NewTestUI(NewSpacer(), 30, 8)
+	defer runTestUI(t, ui)()
+
+	// Popping the layers of the menus closes them, as Close would.
+	ui.Update(func() {
+		m.Show(ui, LayerOptions{})
+		m.selectNext(1)
+		if !m.openSubmenu() {
+			t.Errorf("expected submenu to open")
+			return
+		}
+		ui.PopLayer()
+		ui.PopLayer()
+
+		if m.IsOpen() || recent.IsOpen() {
+			t.Errorf("expected menus to be closed")
+		}
+		m.Show(ui, LayerOptions{})
+		if !m.IsOpen() || len(ui.layers.layers) != 1 {
+			t.Errorf("expected menu to open again")
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package tui
+
+import "image"
+
+// MouseHandler is implemented by widgets that want to receive mouse events.
+type MouseHandler interface {
+	OnMouseEvent(ev MouseEvent)
+}
+
+// walkWidgets calls fn for w and every widget it contains, in the order they
+// are laid out. bounds is the area occupied by the widget in world
+// coordinates, and visible is the part of it that is not masked by any of its
+// containers. Widgets that are entirely masked are skipped, along with their
+// descendants.
+func walkWidgets(w Widget, bounds, visible image.Rectangle, fn func(w Widget, bounds, visible image.Rectangle)) {
+	fn(w, bounds, visible)
+
+	c, ok := w.(Container)
+	if !ok {
+		return
+	}
+	for _, child := range c.Children() {
+		cb := c.ChildBounds(child).Add(bounds.Min)
+		cv := cb.Intersect(visible)
+		if cv.Empty() {
+			continue
+		}
+		walkWidgets(child, cb, cv, fn)
+	}
+}
+
+// rootBounds returns the area occupied by a root widget.
+func rootBounds(root Widget) image.Rectangle {
+	return image.Rectangle{Max: root.Size()}
+}
+
+// widgetAt returns the deepest widget at the given point that handles mouse
+// events, along with the world coordinates of its top-left corner.
+func widgetAt(root Widget, pt image.Point) (MouseHandler, image.Point) {
+	var (
+		target MouseHandler
+		origin image.Point
+	)
+	r := rootBounds(root)
+	walkWidgets(root, r, r, func(w Widget, bounds, visible image.Rectangle) {
+		if !pt.In(visible) {
+			return
+		}
+		if mh, ok := w.(MouseHandler); ok {
+			target = mh
+			origin = bounds.Min
+		}
+	})
+	return target, origin
+}
+
+// widgetsAt returns the widgets at the given point, starting with the root and
+// ending with the deepest one.
+func widgetsAt(root Widget, pt image.Point) []Widget {
+	var ws []Widget
+	r := rootBounds(root)
+	walkWidgets(root, r, r, func(w Widget, bounds, visible image.Rectangle) {
+		if pt.In(visible) {
+			ws = append(ws, w)
+		}
+	})
+	return ws
+}
+
+// widgetOrigin returns the world coordinates of the top-left corner of a
+// widget in the tree.
+func widgetOrigin(root, target Widget) (image.Point, bool) {
+	var (
+		origin image.Point
+		found  bool
+	)
+	r := rootBounds(root)
+	walkWidgets(root, r, r, func(w Widget, bounds, visible image.Rectangle) {
+		if !found && w == target {
+			origin = bounds.Min
+			found = true
+		}
+	})
+	return origin, found
+}
+
+// mouseController delivers mouse events to the widget under the pointer.
+// The widget receiving a button press also receives any drag and release
+// events that follow it, even if the pointer leaves the widget.
+type mouseController struct {
+	grabbed MouseHandler
+}
+
+func (c *mouseController) OnMouseEvent(root Widget, ev MouseEvent) {
+	pt := ev.Pos
+
+	var (
+		target MouseHandler
+		origin image.Point
+	)
+
+	switch ev.Action {
+	case MouseDrag, MouseRelease:
+		// The grabbed widget may have been removed from the tree since
+		// the button was pressed.
+		if w, ok := c.grabbed.(Widget); ok {
+			if o, ok := widgetOrigin(root, w); ok {
+				target, origin = c.grabbed, o
+			}
+		}
+		if ev.Action == MouseRelease {
+			c.grabbed = nil
+		}
+		if target == nil {
+			target, origin = widgetAt(root, pt)
+		}
+	case MousePress:
+		target, origin = widgetAt(root, pt)
+		c.grabbed = target
+	default:
+		target, origin = widgetAt(root, pt)
+	}
+
+	if target == nil {
+		return
+	}
+
+	ev.Pos = pt.Sub(origin)
+	target.OnMouseEvent(ev)
+}
@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"image"
+	"testing"
+)
+
+type mouseRecorder struct {
+	WidgetBase
+
+	events []MouseEvent
+}
+
+func (r *mouseRecorder) SizeHint() image.Point {
+	return image.Point{5, 1}
+}
+
+func (r *mouseRecorder) OnMouseEvent(ev MouseEvent) {
+	r.events = append(r.events, ev)
+}
+
+func TestMouseController_HitTest(t *testing.T) {
+	a := &mouseRecorder{}
+	b := &mouseRecorder{}
+	c := &mouseRecorder{}
+
+	grid := NewGrid(0, 0)
+	grid.SetBorder(true)
+	grid.AppendRow(b, c)
+
+	root := NewVBox(
+		NewPadder(1, 0, a),
+		grid,
+	)
+	root.SetBorder(true)
+	root.Resize(image.Point{20, 8})
+
+	for _, tt := range []struct {
+		test   string
+		pos    image.Point
+		target *mouseRecorder
+		local  image.Point
+	}{
+		{"padded widget", image.Pt(3, 1), a, image.Pt(1, 0)},
+		{"padding", image.Pt(1, 1), nil, image.Point{}},
+		{"first grid cell", image.Pt(2, 5), b, image.Pt(0, 0)},
+		{"second grid cell", image.Pt(12, 5), c, image.Pt(1, 0)},
+		{"grid border", image.Pt(1, 5), nil, image.Point{}},
+		{"outside", image.Pt(25, 3), nil, image.Point{}},
+	} {
+		tt := tt
+		t.Run(tt.test, func(t *testing.T) {
+			for _, r := range []*mouseRecorder{a, b, c} {
+				r.events = nil
+			}
+
+			ctrl := &mouseController{}
+			ctrl.OnMouseEvent(root, MouseEvent{Pos: tt.pos, Action: MouseMove})
+
+			for _, r := range []*mouseRecorder{a, b, c} {
+				if r != tt.target && len(r.events) > 0 {
+					t.Fatalf("unexpected event delivered: %+v", r.events)
+				}
+			}
+			if tt.target == nil {
+				return
+			}
+			if len(tt.target.events) != 1 {
+				t.Fatalf("got %d events; want = 1", len(tt.target.events))
+			}
+			if got := tt.target.events[0].Pos; got != tt.local {
+				t.Errorf("got = %v; want = %v", got, tt.local)
+			}
+		})
+	}
+}
+
+func TestMouseController_Grab(t *testing.T) {
+	a := &mouseRecorder{}
+	b := &mouseRecorder{}
+
+	root := NewHBox(a, b)
+	root.Resize(image.Point{10, 1})
+
+	ctrl := &mouseController{}
+	ctrl.OnMouseEvent(root, MouseEvent{Pos: image.Pt(1, 0), Action: MousePress, Button: MouseButtonLeft})
+	ctrl.OnMouseEvent(root, MouseEvent{Pos: image.Pt(7, 0), Action: MouseDrag, Button: MouseButtonLeft})
+	ctrl.OnMouseEvent(root, MouseEvent{Pos: image.Pt(8, 0), Action: MouseRelease, Button: MouseButtonLeft})
+	ctrl.OnMouseEvent(root, MouseEvent{Pos: image.Pt(8, 0), Action: MouseMove})
+
+	if len(a.events) != 3 {
+		t.Fatalf("got %d events; want = 3", len(a.events))
+	}
+	if got, want := a.events[2].Pos, image.Pt(8, 0); got != want {
+		t.Errorf("got = %v; want = %v", got, want)
+	}
+	if len(b.events) != 1 || b.events[0].Action != MouseMove {
+		t.Errorf("got = %+v; want a single move event", b.events)
+	}
+}
+
+func TestMouseController_ScrollArea(t *testing.T) {
+	a := &mouseRecorder{}
+	b := &mouseRecorder{}
+
+	s := NewScrollArea(NewVBox(NewLabel("foo"), a, b))
+	s.Resize(image.Point{5, 2})
+	s.Scroll(0, 1)
+
+	ctrl := &mouseController{}
+	ctrl.OnMouseEvent(s, MouseEvent{Pos: image.Pt(2, 1), Action: MouseMove})
+
+	if len(b.events) != 1 {
+		t.Fatalf("got %d events; want = 1", len(b.events))
+	}
+	if got, want := b.events[0].Pos, image.Pt(2, 0); got != want {
+		t.Errorf("got = %v; want = %v", got, want)
+	}
+
+	// Wheel events are delivered to the widget under the pointer if it
+	// handles mouse events.
+	ctrl.OnMouseEvent(s, MouseEvent{Pos: image.Pt(0, 0), Action: MouseWheel, Wheel: WheelUp})
+	if len(a.events) != 1 {
+		t.Fatalf("got %d events; want = 1", len(a.events))
+	}
+
+	s.Widget = NewVBox(NewLabel("foo"), NewLabel("bar"), NewLabel("baz"))
+	s.Resize(image.Point{5, 2})
+	s.ScrollToTop()
+
+	// Otherwise they end up in the scroll area, which stops scrolling at
+	// the bottom of the content.
+	for i := 0; i < 3; i++ {
+		ctrl.OnMouseEvent(s, MouseEvent{Pos: image.Pt(0, 0), Action: MouseWheel, Wheel: WheelDown})
+	}
+
+	surface := NewTestSurface(5, 2)
+	painter := NewPainter(surface, NewTheme())
+	painter.Repaint(s)
+
+	want := `
+bar..
+baz..
+`
+	if diff := surfaceEquals(surface, want); diff != "" {
+		t.Error(diff)
+	}
+}
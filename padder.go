@@ -3,6 +3,7 @@ package tui
 import "image"
 
 var _ Widget = &Padder{}
+var _ Container = &Padder{}
 
 // Padder is a widget to fill out space.
 // It adds empty space of a specified size to the outside of its contained Widget.
@@ -27,7 +28,7 @@ func (p *Padder) Draw(painter *Painter) {
 	painter.Translate(p.padding.X, p.padding.Y)
 	defer painter.Restore()
 
-	p.widget.Draw(painter)
+	painter.DrawWidget(p.widget)
 }
 
 // Size returns the size of the padded widget.
@@ -55,9 +56,22 @@ func (p *Padder) Resize(size image.Point) {
 	p.widget.Resize(size.Sub(p.padding.Mul(2)))
 }
 
-// OnKeyEvent handles key events.
+// OnKeyEvent does nothing. Key events reach the padded widget before the
+// Padder.
 func (p *Padder) OnKeyEvent(ev KeyEvent) {
-	p.widget.OnKeyEvent(ev)
+}
+
+// Children returns the padded widget.
+func (p *Padder) Children() []Widget {
+	return []Widget{p.widget}
+}
+
+// ChildBounds returns the area occupied by the padded widget.
+func (p *Padder) ChildBounds(w Widget) image.Rectangle {
+	if w != p.widget {
+		return image.Rectangle{}
+	}
+	return image.Rectangle{Min: p.padding, Max: p.padding.Add(w.Size())}
 }
 
 // SetFocused set the focus on the widget.
@@ -28,6 +28,51 @@ type Painter struct {
 	transforms []image.Point
 
 	mask image.Rectangle
+
+	// front holds what was painted on the surface in the previous frame,
+	// and back what's being painted in the current one. back is only set
+	// while repainting.
+	front, back *cellBuffer
+
+	// ops records every cell painted in the current frame, in order, while
+	// records holds the part of ops painted by each widget that tracks its
+	// dirty state. The ones from the previous frame are kept so that
+	// widgets that haven't changed can be replayed rather than redrawn.
+	ops, prevOps         []paintOp
+	records, prevRecords map[Widget]drawRecord
+
+	// cursor is where the cursor was last drawn in the current frame, if
+	// hasCursor is set.
+	cursor    image.Point
+	hasCursor bool
+}
+
+// paintOp is a cell, or the cursor, painted in world coordinates.
+type paintOp struct {
+	pos    image.Point
+	ch     rune
+	style  Style
+	cursor bool
+}
+
+// drawState is the state of the painter when a widget is drawn. A widget
+// can only be replayed if it's drawn with the same state as in the previous
+// frame.
+type drawState struct {
+	origin image.Point
+	mask   image.Rectangle
+	style  Style
+	theme  *Theme
+
+	// themeRev is the revision of the theme, which changes with its
+	// styles.
+	themeRev int
+}
+
+// drawRecord holds the range of paint operations made by a widget.
+type drawRecord struct {
+	state      drawState
+	start, end int
 }
 
 // NewPainter returns a new instance of Painter.
@@ -65,33 +110,180 @@ func (p *Painter) End() {
 	p.surface.End()
 }
 
-// Repaint clears the surface, draws the scene and flushes it.
+// Repaint draws the scene and flushes it. Only the cells that changed since
+// the previous Repaint are painted on the surface. The surface is cleared on
+// the first Repaint, whenever its size changes, and after Invalidate.
 func (p *Painter) Repaint(w Widget) {
+	size := p.surface.Size()
+
 	p.mask = image.Rectangle{
 		Min: image.Point{},
-		Max: p.surface.Size(),
+		Max: size,
 	}
 
 	p.surface.HideCursor()
+	p.hasCursor = false
 
-	w.Resize(p.surface.Size())
+	w.Resize(size)
+
+	if p.front == nil || p.front.size != size {
+		p.Begin()
+		p.front = newCellBuffer(size)
+	}
+	p.back = newCellBuffer(size)
+
+	p.prevOps, p.ops = p.ops, p.prevOps[:0]
+	p.prevRecords, p.records = p.records, make(map[Widget]drawRecord)
+
+	p.DrawWidget(w)
+
+	p.back.flushTo(p.surface, p.front)
+	p.front, p.back = p.back, nil
 
-	p.Begin()
-	w.Draw(p)
 	p.End()
 }
 
+// Invalidate discards the previous frame, so that the next Repaint clears the
+// surface and paints every cell.
+func (p *Painter) Invalidate() {
+	p.front = nil
+	p.records = nil
+}
+
+// DrawWidget draws a widget at the current position. Containers use it to
+// draw their children.
+//
+// Widgets that track their dirty state, and haven't changed since the
+// previous frame, aren't drawn again. Instead, the cells they painted in the
+// previous frame are painted again.
+func (p *Painter) DrawWidget(w Widget) {
+	if p.back == nil {
+		w.Draw(p)
+		return
+	}
+
+	dt, ok := dirtyTracker(w)
+	if !ok {
+		w.Draw(p)
+		return
+	}
+
+	state := drawState{
+		origin:   p.mapLocalToWorld(image.Point{}),
+		mask:     p.mask,
+		style:    p.style,
+		theme:    p.theme,
+		themeRev: p.theme.rev,
+	}
+
+	start := len(p.ops)
+
+	if rec, ok := p.prevRecords[w]; ok && rec.state == state && isClean(w) {
+		for _, op := range p.prevOps[rec.start:rec.end] {
+			p.apply(op)
+		}
+		p.records[w] = drawRecord{state: state, start: start, end: len(p.ops)}
+		p.keepRecords(w, start-rec.start)
+		return
+	}
+
+	w.Draw(p)
+	dt.MarkClean()
+
+	p.records[w] = drawRecord{state: state, start: start, end: len(p.ops)}
+}
+
+// keepRecords carries over the records of the descendants of a replayed
+// widget to the current frame, where their operations are offset by delta.
+func (p *Painter) keepRecords(w Widget, delta int) {
+	c, ok := w.(Container)
+	if !ok {
+		return
+	}
+	for _, child := range c.Children() {
+		if rec, ok := p.prevRecords[child]; ok {
+			rec.start += delta
+			rec.end += delta
+			p.records[child] = rec
+		}
+		p.keepRecords(child, delta)
+	}
+}
+
+// dirtyTracker returns the dirty tracker of w, if its dirty state can be
+// relied on. A type that embeds a built-in widget inherits its dirty state,
+// but not necessarily the way it's drawn, so it's always redrawn.
+func dirtyTracker(w Widget) (DirtyTracker, bool) {
+	dt, ok := w.(DirtyTracker)
+	if !ok {
+		return nil, false
+	}
+	if o, ok := w.(interface{ dirtyOwner() Widget }); ok {
+		if owner := o.dirtyOwner(); owner != nil && owner != w {
+			return nil, false
+		}
+	}
+	return dt, true
+}
+
+// isClean returns whether neither w nor any of the widgets it contains need to
+// be redrawn.
+func isClean(w Widget) bool {
+	dt, ok := dirtyTracker(w)
+	if !ok || dt.IsDirty() {
+		return false
+	}
+	if c, ok := w.(Container); ok {
+		for _, child := range c.Children() {
+			if !isClean(child) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// restyle applies a style on top of every cell painted so far in the current
+// frame. It does nothing when painting outside of Repaint.
+func (p *Painter) restyle(s Style) {
+	if p.back == nil {
+		return
+	}
+	for i, c := range p.back.cells {
+		if c.set {
+			p.back.cells[i].Style = c.Style.mergeIn(s)
+		}
+	}
+}
+
+// apply performs a paint operation in the current frame.
+func (p *Painter) apply(op paintOp) {
+	if p.back != nil {
+		p.ops = append(p.ops, op)
+	}
+	if op.cursor {
+		p.cursor, p.hasCursor = op.pos, true
+		p.surface.SetCursor(op.pos.X, op.pos.Y)
+		return
+	}
+	if p.back != nil {
+		p.back.setCell(op.pos.X, op.pos.Y, op.ch, op.style)
+		return
+	}
+	p.surface.SetCell(op.pos.X, op.pos.Y, op.ch, op.style)
+}
+
 // DrawCursor draws the cursor at the given position.
 func (p *Painter) DrawCursor(x, y int) {
 	wp := p.mapLocalToWorld(image.Point{x, y})
-	p.surface.SetCursor(wp.X, wp.Y)
+	p.apply(paintOp{pos: wp, cursor: true})
 }
 
 // DrawRune paints a rune at the given coordinate.
 func (p *Painter) DrawRune(x, y int, r rune) {
 	wp := p.mapLocalToWorld(image.Point{x, y})
 	if (p.mask.Min.X <= wp.X) && (wp.X < p.mask.Max.X) && (p.mask.Min.Y <= wp.Y) && (wp.Y < p.mask.Max.Y) {
-		p.surface.SetCell(wp.X, wp.Y, r, p.style)
+		p.apply(paintOp{pos: wp, ch: r, style: p.style})
 	}
 }
 
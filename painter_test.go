@@ -2,6 +2,7 @@ package tui
 
 import (
 	"image"
+	"strconv"
 	"testing"
 )
 
@@ -407,3 +408,206 @@ func TestWithStyle_Inherit(t *testing.T) {
 		})
 	}
 }
+
+// countingSurface counts the cells painted on a TestSurface.
+type countingSurface struct {
+	*TestSurface
+
+	begins int
+	cells  int
+}
+
+func (s *countingSurface) SetCell(x, y int, ch rune, style Style) {
+	s.cells++
+	s.TestSurface.SetCell(x, y, ch, style)
+}
+
+func (s *countingSurface) Begin() {
+	s.begins++
+	s.TestSurface.Begin()
+}
+
+func TestPainter_Repaint_Differential(t *testing.T) {
+	surface := &countingSurface{TestSurface: NewTestSurface(5, 2)}
+	painter := NewPainter(surface, NewTheme())
+
+	l := NewLabel("foo")
+	root := NewVBox(l, NewLabel("bar"))
+
+	painter.Repaint(root)
+	if surface.begins != 1 {
+		t.Errorf("got = %d; want = %d", surface.begins, 1)
+	}
+
+	// Nothing changed, so nothing should be painted.
+	surface.cells = 0
+	painter.Repaint(root)
+	if surface.cells != 0 {
+		t.Errorf("got = %d; want = %d", surface.cells, 0)
+	}
+
+	// Only the changed cells should be painted.
+	l.SetText("fox")
+	painter.Repaint(root)
+	if surface.cells != 1 {
+		t.Errorf("got = %d; want = %d", surface.cells, 1)
+	}
+	if surface.begins != 1 {
+		t.Errorf("got = %d; want = %d", surface.begins, 1)
+	}
+
+	want := `
+fox  
+bar  
+`
+	if diff := surfaceEquals(surface.TestSurface, want); diff != "" {
+		t.Error(diff)
+	}
+
+	// Invalidating the painter clears the surface and paints everything.
+	surface.cells = 0
+	painter.Invalidate()
+	painter.Repaint(root)
+	if surface.begins != 2 {
+		t.Errorf("got = %d; want = %d", surface.begins, 2)
+	}
+	if surface.cells != 10 {
+		t.Errorf("got = %d; want = %d", surface.cells, 10)
+	}
+}
+
+type drawCounter struct {
+	WidgetBase
+
+	text  string
+	draws int
+}
+
+func newDrawCounter(text string) *drawCounter {
+	w := &drawCounter{text: text}
+	w.SetDirtyTracking(true)
+	return w
+}
+
+func (w *drawCounter) Draw(p *Painter) {
+	w.draws++
+	p.WithStyle("counter", func(p *Painter) {
+		p.DrawText(0, 0, w.text)
+	})
+}
+
+func (w *drawCounter) SizeHint() image.Point {
+	return image.Point{len(w.text), 1}
+}
+
+func TestPainter_Repaint_SkipsCleanWidgets(t *testing.T) {
+	surface := NewTestSurface(5, 2)
+	theme := NewTheme()
+	painter := NewPainter(surface, theme)
+
+	a := newDrawCounter("a")
+	b := newDrawCounter("b")
+	box := NewVBox(a, b)
+	root := NewPadder(1, 0, box)
+
+	painter.Repaint(root)
+	painter.Repaint(root)
+
+	if a.draws != 1 || b.draws != 1 {
+		t.Errorf("got = %d, %d; want = 1, 1", a.draws, b.draws)
+	}
+
+	want := `
+.a  .
+.b  .
+`
+	if diff := surfaceEquals(surface, want); diff != "" {
+		t.Error(diff)
+	}
+
+	// Only the dirty widget is redrawn.
+	b.text = "c"
+	b.MarkDirty()
+	painter.Repaint(root)
+
+	if a.draws != 1 || b.draws != 2 {
+		t.Errorf("got = %d, %d; want = 1, 2", a.draws, b.draws)
+	}
+
+	want = `
+.a  .
+.c  .
+`
+	if diff := surfaceEquals(surface, want); diff != "" {
+		t.Error(diff)
+	}
+
+	// Widgets are redrawn when their position changes.
+	box.Prepend(NewSpacer())
+	box.Resize(image.Point{3, 2})
+	painter.Repaint(NewPadder(2, 0, box))
+
+	if a.draws != 2 || b.draws != 3 {
+		t.Errorf("got = %d, %d; want = 2, 3", a.draws, b.draws)
+	}
+
+	// Widgets are redrawn when the theme changes.
+	painter.theme = NewTheme()
+	painter.Repaint(root)
+
+	if a.draws != 3 || b.draws != 4 {
+		t.Errorf("got = %d, %d; want = 3, 4", a.draws, b.draws)
+	}
+
+	// And when a style of the theme in use changes.
+	painter.theme.SetStyle("counter", Style{Bold: DecorationOn})
+	painter.Repaint(root)
+
+	if a.draws != 4 || b.draws != 5 {
+		t.Errorf("got = %d, %d; want = 4, 5", a.draws, b.draws)
+	}
+}
+
+// countLabel embeds a Label, but draws a counter of its own.
+type countLabel struct {
+	*Label
+	n int
+}
+
+func (l *countLabel) Draw(p *Painter) {
+	p.DrawText(0, 0, strconv.Itoa(l.n))
+}
+
+func TestPainter_Repaint_EmbeddedWidgets(t *testing.T) {
+	surface := NewTestSurface(3, 1)
+	painter := NewPainter(surface, NewTheme())
+
+	l := &countLabel{Label: NewLabel("")}
+	root := NewHBox(l)
+
+	painter.Repaint(root)
+	l.n = 1
+	painter.Repaint(root)
+
+	// The label is clean, but the widget embedding it is redrawn anyway.
+	want := `
+1  
+`
+	if diff := surfaceEquals(surface, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestPainter_Repaint_UntrackedWidgets(t *testing.T) {
+	surface := NewTestSurface(5, 1)
+	painter := NewPainter(surface, NewTheme())
+
+	w := &drawCounter{text: "a"}
+
+	painter.Repaint(NewHBox(w))
+	painter.Repaint(NewHBox(w))
+
+	if w.draws != 2 {
+		t.Errorf("got = %d; want = %d", w.draws, 2)
+	}
+}
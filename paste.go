@@ -0,0 +1,178 @@
+package tui
+
+import "time"
+
+// PasteHandler is implemented by widgets that accept pasted text. The UI
+// sends a PasteEvent to the first widget implementing PasteHandler, starting
+// with the focused widget and moving up through the containers it's in. If
+// no widget accepts the paste, the text is sent as key presses instead.
+type PasteHandler interface {
+	OnPasteEvent(ev PasteEvent)
+}
+
+// The escape sequences enabling and disabling bracketed paste mode, where
+// the terminal wraps pasted text in the paste markers below.
+const (
+	enableBracketedPaste  = "\x1b[?2004h"
+	disableBracketedPaste = "\x1b[?2004l"
+)
+
+// pasteMarkerTimeout is how long to wait for the rest of a paste marker. The
+// keys of a marker arrive together, so anything slower was typed by hand.
+const pasteMarkerTimeout = 50 * time.Millisecond
+
+// pasteEndTimeout is how long to wait for more of the pasted text. If the end
+// marker is lost, e.g. because the connection dropped bytes, the text pasted
+// so far is delivered once nothing more arrives, rather than holding every
+// key typed afterwards.
+const pasteEndTimeout = 500 * time.Millisecond
+
+// The paste markers, "ESC [ 200 ~" and "ESC [ 201 ~", as they are reported by
+// tcell: the escape followed by '[' is read as Alt+[.
+var (
+	pasteStart = pasteMarker("200~")
+	pasteEnd   = pasteMarker("201~")
+)
+
+func pasteMarker(s string) []KeyEvent {
+	evs := []KeyEvent{{Key: KeyRune, Rune: '[', Modifiers: ModAlt}}
+	for _, r := range s {
+		evs = append(evs, KeyEvent{Key: KeyRune, Rune: r})
+	}
+	return evs
+}
+
+// pasteDecoder turns the key events between two paste markers into a single
+// PasteEvent. Keys that look like the beginning of a marker are held until
+// it's clear whether they are.
+type pasteDecoder struct {
+	held    []KeyEvent
+	pasting bool
+	text    []rune
+}
+
+// feed decodes a key event, and returns the events that are ready to be
+// handled.
+func (d *pasteDecoder) feed(ev KeyEvent) []event {
+	marker := pasteStart
+	if d.pasting {
+		marker = pasteEnd
+	}
+
+	d.held = append(d.held, ev)
+
+	switch matchKeys(d.held, marker) {
+	case matchPrefix:
+		return nil
+	case matchFull:
+		d.held = nil
+		if !d.pasting {
+			d.pasting = true
+			return nil
+		}
+		text := string(d.text)
+		d.pasting = false
+		d.text = nil
+		return []event{PasteEvent{Text: text}}
+	}
+
+	// Not a marker after all. The last key might still begin one.
+	keys := d.held
+	d.held = nil
+	if last := keys[len(keys)-1:]; matchKeys(last, marker) == matchPrefix {
+		d.held = last
+		keys = keys[:len(keys)-1]
+	}
+
+	if d.pasting {
+		for _, k := range keys {
+			d.text = append(d.text, keyText(k)...)
+		}
+		return nil
+	}
+
+	evs := make([]event, len(keys))
+	for i, k := range keys {
+		evs[i] = k
+	}
+	return evs
+}
+
+// timeout returns how long to wait for more keys before calling flush, or
+// zero if no keys are being held back.
+func (d *pasteDecoder) timeout() time.Duration {
+	switch {
+	case d.pasting:
+		return pasteEndTimeout
+	case len(d.held) > 0:
+		return pasteMarkerTimeout
+	}
+	return 0
+}
+
+// flush releases the keys held back while waiting for a paste start marker.
+// While pasting, it ends the paste as if the end marker had arrived.
+func (d *pasteDecoder) flush() []event {
+	if d.pasting {
+		text := d.text
+		for _, k := range d.held {
+			text = append(text, keyText(k)...)
+		}
+		d.held = nil
+		d.pasting = false
+		d.text = nil
+		return []event{PasteEvent{Text: string(text)}}
+	}
+	evs := make([]event, len(d.held))
+	for i, k := range d.held {
+		evs[i] = k
+	}
+	d.held = nil
+	return evs
+}
+
+// matchKeys returns whether the keys match the whole marker, or only its
+// beginning.
+func matchKeys(keys, marker []KeyEvent) matchResult {
+	if len(keys) > len(marker) {
+		return matchNone
+	}
+	for i, k := range keys {
+		m := marker[i]
+		if k.Key != m.Key || k.Rune != m.Rune || k.Modifiers != m.Modifiers {
+			return matchNone
+		}
+	}
+	if len(keys) < len(marker) {
+		return matchPrefix
+	}
+	return matchFull
+}
+
+// keyText returns the text a key press stands for within pasted text.
+func keyText(ev KeyEvent) []rune {
+	switch {
+	case ev.Key == KeyRune && ev.Modifiers&ModAlt != 0:
+		// tcell reads an escape followed by a character as Alt.
+		return []rune{'\x1b', ev.Rune}
+	case ev.Key == KeyRune:
+		return []rune{ev.Rune}
+	case ev.Key == KeyEnter:
+		return []rune{'\n'}
+	case ev.Key < KeyRune:
+		// Other control characters, e.g. tabs and line feeds.
+		return []rune{rune(ev.Key)}
+	}
+	return nil
+}
+
+// keyForRune returns the key press that types the rune.
+func keyForRune(r rune) KeyEvent {
+	switch r {
+	case '\n':
+		return KeyEvent{Key: KeyEnter, Rune: r}
+	case '\t':
+		return KeyEvent{Key: KeyTab, Rune: r}
+	}
+	return KeyEvent{Key: KeyRune, Rune: r}
+}
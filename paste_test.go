@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func keyEvents(s string) []KeyEvent {
+	var evs []KeyEvent
+	for _, r := range s {
+		evs = append(evs, keyForRune(r))
+	}
+	return evs
+}
+
+func TestPasteDecoder(t *testing.T) {
+	altBracket := KeyEvent{Key: KeyRune, Rune: '[', Modifiers: ModAlt}
+
+	var in []KeyEvent
+	in = append(in, keyEvents("a")...)
+	in = append(in, pasteStart...)
+	in = append(in, keyEvents("hi\tthere")...)
+	in = append(in, KeyEvent{Key: KeyEnter, Rune: '\r'})
+	in = append(in, altBracket)
+	in = append(in, keyEvents("20")...)
+	in = append(in, pasteEnd...)
+	in = append(in, altBracket)
+	in = append(in, keyEvents("b")...)
+	in = append(in, altBracket)
+
+	var d pasteDecoder
+	var got []event
+	for _, ev := range in {
+		got = append(got, d.feed(ev)...)
+	}
+
+	want := []event{
+		keyForRune('a'),
+		PasteEvent{Text: "hi\tthere\n\x1b[20"},
+		altBracket,
+		keyForRune('b'),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v; want = %v", got, want)
+	}
+
+	// The last Alt+[ is held until it's clear it doesn't begin a marker.
+	if d.timeout() != pasteMarkerTimeout {
+		t.Fatalf("expected decoder to be waiting for a marker")
+	}
+	if got := d.flush(); !reflect.DeepEqual(got, []event{altBracket}) {
+		t.Errorf("got = %v; want = %v", got, []event{altBracket})
+	}
+	if d.timeout() != 0 {
+		t.Errorf("expected decoder not to be waiting")
+	}
+}
+
+func TestPasteDecoder_MissingEnd(t *testing.T) {
+	var d pasteDecoder
+	for _, ev := range append(pasteStart, keyEvents("hi")...) {
+		if got := d.feed(ev); got != nil {
+			t.Fatalf("got = %v; want nothing until the paste ends", got)
+		}
+	}
+
+	// The end marker never arrives. The text pasted so far is delivered
+	// once the decoder gives up waiting.
+	if d.timeout() != pasteEndTimeout {
+		t.Fatalf("expected decoder to be waiting for the end of the paste")
+	}
+	want := []event{PasteEvent{Text: "hi"}}
+	if got := d.flush(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v; want = %v", got, want)
+	}
+
+	// Keys typed afterwards are keys again.
+	want = []event{keyForRune('x')}
+	if got := d.feed(keyForRune('x')); !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v; want = %v", got, want)
+	}
+}
+
+func TestTestUI_Paste(t *testing.T) {
+	e := NewEntry()
+	e.SetFocused(true)
+
+	var changes, submits int
+	e.OnChanged(func(*Entry) { changes++ })
+	e.OnSubmit(func(*Entry) { submits++ })
+
+	ui := NewTestUI(e, 10, 1)
+	defer runTestUI(t, ui)()
+
+	ui.Paste("foo\nbar")
+
+	want := `
+foo bar   
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+
+	ui.Update(func() {
+		if changes != 1 {
+			t.Errorf("got = %d; want = %d", changes, 1)
+		}
+		if submits != 0 {
+			t.Errorf("got = %d; want = %d", submits, 0)
+		}
+	})
+}
+
+func TestTestUI_PasteWithoutHandler(t *testing.T) {
+	var got string
+
+	ui := NewTestUI(NewLabel(""), 10, 1)
+	ui.SetKeybinding("a", func() { got += "a" })
+	ui.SetKeybinding("Enter", func() { got += "!" })
+	defer runTestUI(t, ui)()
+
+	ui.Paste("aba\n")
+	ui.WaitIdle()
+
+	ui.Update(func() {
+		if got != "aa!" {
+			t.Errorf("got = %q; want = %q", got, "aa!")
+		}
+	})
+}
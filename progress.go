@@ -13,9 +13,11 @@ type Progress struct {
 
 // NewProgress returns a new Progress.
 func NewProgress(max int) *Progress {
-	return &Progress{
+	p := &Progress{
 		max: max,
 	}
+	p.trackDirtyFor(p)
+	return p
 }
 
 // Draw draws the progress bar.
@@ -48,10 +50,12 @@ func (p *Progress) SizeHint() image.Point {
 
 // SetCurrent sets the current progress.
 func (p *Progress) SetCurrent(c int) {
+	p.MarkDirty()
 	p.current = c
 }
 
 // SetMax sets the maximum progress.
 func (p *Progress) SetMax(m int) {
+	p.MarkDirty()
 	p.max = m
 }
@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"time"
+)
+
+var _ Surface = &Recorder{}
+
+// Recorder is a Surface that paints on another surface, and records every
+// frame as an asciicast v2 recording, which can be played back with the
+// asciinema player, for instance. Each frame is recorded as the escape
+// sequences that draw the cells that changed since the previous frame, at
+// the time it was flushed.
+//
+// To record a UI, use its Record method.
+type Recorder struct {
+	surface Surface
+
+	w     io.Writer
+	start time.Time
+	now   func() time.Time
+
+	// ansi turns the frames into escape sequences, which are collected in
+	// buf.
+	ansi *ANSISurface
+	buf  bytes.Buffer
+
+	started bool
+	err     error
+}
+
+// NewRecorder returns a surface that paints on s, and writes the recording to
+// w. The time of each frame is relative to when the recorder was created.
+func NewRecorder(w io.Writer, s Surface) *Recorder {
+	r := &Recorder{
+		surface: s,
+		w:       w,
+		start:   time.Now(),
+		now:     time.Now,
+	}
+	r.ansi = NewANSISurface(&r.buf, 0, 0, ANSIFull)
+	return r
+}
+
+// SetCell sets the contents of a cell.
+func (r *Recorder) SetCell(x, y int, ch rune, s Style) {
+	r.surface.SetCell(x, y, ch, s)
+	r.ansi.SetCell(x, y, ch, s)
+}
+
+// SetCursor shows the cursor at the given position.
+func (r *Recorder) SetCursor(x, y int) {
+	r.surface.SetCursor(x, y)
+	r.ansi.SetCursor(x, y)
+}
+
+// HideCursor hides the cursor.
+func (r *Recorder) HideCursor() {
+	r.surface.HideCursor()
+	r.ansi.HideCursor()
+}
+
+// Begin clears the surface.
+func (r *Recorder) Begin() {
+	r.surface.Begin()
+	r.resize()
+	r.ansi.Begin()
+}
+
+// End flushes the frame, and records it.
+func (r *Recorder) End() {
+	r.surface.End()
+	r.resize()
+	r.ansi.End()
+
+	r.record("o", r.buf.String())
+	r.buf.Reset()
+}
+
+// Size returns the size of the surface.
+func (r *Recorder) Size() image.Point {
+	return r.surface.Size()
+}
+
+// Err returns the first error that occurred while writing the recording, if
+// any. Nothing more is recorded after an error.
+func (r *Recorder) Err() error {
+	return r.err
+}
+
+// resize follows the size of the surface, and records it when it changes.
+func (r *Recorder) resize() {
+	size := r.surface.Size()
+	if size == r.ansi.Size() {
+		return
+	}
+	r.ansi.SetSize(size.X, size.Y)
+	if r.started {
+		r.record("r", fmt.Sprintf("%dx%d", size.X, size.Y))
+	}
+}
+
+// record writes an event, after the header if it's the first one.
+func (r *Recorder) record(kind, data string) {
+	if r.err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if !r.started {
+		size := r.ansi.Size()
+		enc.Encode(asciicastHeader{
+			Version:   2,
+			Width:     size.X,
+			Height:    size.Y,
+			Timestamp: r.start.Unix(),
+		})
+		r.started = true
+	}
+
+	// Times are in seconds, with microsecond precision.
+	t := math.Round(r.now().Sub(r.start).Seconds()*1e6) / 1e6
+	enc.Encode([]interface{}{t, kind, data})
+
+	if _, err := r.w.Write(buf.Bytes()); err != nil {
+		r.err = err
+	}
+}
+
+// asciicastHeader is the first line of an asciicast v2 recording.
+type asciicastHeader struct {
+	Version   int   `json:"version"`
+	Width     int   `json:"width"`
+	Height    int   `json:"height"`
+	Timestamp int64 `json:"timestamp"`
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	var buf bytes.Buffer
+
+	start := time.Unix(1500000000, 0)
+	now := start
+
+	s := NewANSISurface(ioutil.Discard, 3, 1, ANSIFull)
+	r := NewRecorder(&buf, s)
+	r.start = start
+	r.now = func() time.Time { return now }
+
+	label := NewLabel("ab")
+	p := NewPainter(r, NewTheme())
+	p.Repaint(label)
+
+	now = now.Add(1500 * time.Millisecond)
+	label.SetText("ax")
+	p.Repaint(label)
+
+	// Resizing the surface is recorded before the frame painted at the new
+	// size.
+	now = now.Add(250 * time.Millisecond)
+	s.SetSize(2, 1)
+	p.Repaint(label)
+
+	want := []string{
+		`{"version":2,"width":3,"height":1,"timestamp":1500000000}`,
+		`[0,"o","\u001b[?25l\u001b[0m\u001b[H\u001b[2J\u001b[1;1H\u001b[0mab\u001b[0m"]`,
+		`[1.5,"o","\u001b[?25l\u001b[1;2H\u001b[0mx\u001b[0m"]`,
+		`[1.75,"r","2x1"]`,
+		`[1.75,"o","\u001b[?25l\u001b[0m\u001b[H\u001b[2J\u001b[1;1H\u001b[0max\u001b[0m"]`,
+	}
+	got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines; want %d:\n%s", len(got), len(want), buf.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: got = %s; want = %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestUI_Record(t *testing.T) {
+	var buf bytes.Buffer
+
+	ui := NewTestUI(NewLabel("hello"), 10, 2)
+	ui.Record(&buf)
+
+	defer runTestUI(t, ui)()
+	ui.Update(func() {})
+
+	// The UI keeps painting on its own surface while recording.
+	want := `
+hello.....
+..........
+`
+	if diff := surfaceEquals(ui.surface, want); diff != "" {
+		t.Error(diff)
+	}
+
+	ui.Update(func() { ui.Record(nil) })
+	if !strings.HasPrefix(buf.String(), `{"version":2,"width":10,"height":2,`) {
+		t.Errorf("got = %q; want an asciicast header", buf.String())
+	}
+	if !strings.Contains(buf.String(), "hello") {
+		t.Errorf("got = %q; want a frame showing the label", buf.String())
+	}
+}
@@ -5,6 +5,8 @@ import (
 )
 
 var _ Widget = &ScrollArea{}
+var _ Container = &ScrollArea{}
+var _ MouseHandler = &ScrollArea{}
 
 // ScrollArea is a widget to fill out space.
 type ScrollArea struct {
@@ -18,9 +20,11 @@ type ScrollArea struct {
 
 // NewScrollArea returns a new ScrollArea.
 func NewScrollArea(w Widget) *ScrollArea {
-	return &ScrollArea{
+	s := &ScrollArea{
 		Widget: w,
 	}
+	s.trackDirtyFor(s)
+	return s
 }
 
 // MinSizeHint returns the minimum size the widget is allowed to be.
@@ -35,17 +39,20 @@ func (s *ScrollArea) SizeHint() image.Point {
 
 // Scroll shifts the views over the content.
 func (s *ScrollArea) Scroll(dx, dy int) {
+	s.MarkDirty()
 	s.topLeft.X += dx
 	s.topLeft.Y += dy
 }
 
 // ScrollToBottom ensures the bottom-most part of the scroll area is visible.
 func (s *ScrollArea) ScrollToBottom() {
+	s.MarkDirty()
 	s.topLeft.Y = s.Widget.SizeHint().Y - s.Size().Y
 }
 
 // ScrollToTop resets the vertical scroll position.
 func (s *ScrollArea) ScrollToTop() {
+	s.MarkDirty()
 	s.topLeft.Y = 0
 }
 
@@ -61,7 +68,7 @@ func (s *ScrollArea) Draw(p *Painter) {
 
 	off := image.Point{s.topLeft.X, s.topLeft.Y}
 	p.WithMask(image.Rectangle{Min: off, Max: s.Size().Add(off)}, func(p *Painter) {
-		s.Widget.Draw(p)
+		p.DrawWidget(s.Widget)
 	})
 }
 
@@ -74,3 +81,45 @@ func (s *ScrollArea) Resize(size image.Point) {
 		s.ScrollToBottom()
 	}
 }
+
+// Children returns the scrolled widget.
+func (s *ScrollArea) Children() []Widget {
+	return []Widget{s.Widget}
+}
+
+// ChildBounds returns the area occupied by the scrolled widget. The area is
+// offset by the current scroll position.
+func (s *ScrollArea) ChildBounds(w Widget) image.Rectangle {
+	if w != s.Widget {
+		return image.Rectangle{}
+	}
+	min := image.Point{-s.topLeft.X, -s.topLeft.Y}
+	return image.Rectangle{Min: min, Max: min.Add(w.Size())}
+}
+
+// OnMouseEvent scrolls the content when the mouse wheel is used. Scrolling
+// stops at the edges of the content.
+func (s *ScrollArea) OnMouseEvent(ev MouseEvent) {
+	if ev.Action != MouseWheel {
+		return
+	}
+	max := s.Widget.Size().Sub(s.Size())
+	switch ev.Wheel {
+	case WheelUp:
+		if s.topLeft.Y > 0 {
+			s.Scroll(0, -1)
+		}
+	case WheelDown:
+		if s.topLeft.Y < max.Y {
+			s.Scroll(0, 1)
+		}
+	case WheelLeft:
+		if s.topLeft.X > 0 {
+			s.Scroll(-1, 0)
+		}
+	case WheelRight:
+		if s.topLeft.X < max.X {
+			s.Scroll(1, 0)
+		}
+	}
+}
@@ -11,7 +11,9 @@ type Spacer struct {
 
 // NewSpacer returns a new Spacer.
 func NewSpacer() *Spacer {
-	return &Spacer{}
+	s := &Spacer{}
+	s.trackDirtyFor(s)
+	return s
 }
 
 // MinSizeHint returns the minimum size the widget is allowed to be.
@@ -16,10 +16,12 @@ type StatusBar struct {
 
 // NewStatusBar returns a new StatusBar.
 func NewStatusBar(text string) *StatusBar {
-	return &StatusBar{
+	b := &StatusBar{
 		text:     text,
 		permText: "",
 	}
+	b.trackDirtyFor(b)
+	return b
 }
 
 // Draw draws the status bar.
@@ -43,10 +45,12 @@ func (b *StatusBar) SizePolicy() (SizePolicy, SizePolicy) {
 
 // SetText sets the text content of the status bar.
 func (b *StatusBar) SetText(text string) {
+	b.MarkDirty()
 	b.text = text
 }
 
 // SetPermanentText sets the permanent text of the status bar.
 func (b *StatusBar) SetPermanentText(text string) {
+	b.MarkDirty()
 	b.permText = text
 }
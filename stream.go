@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"strings"
+
+	"github.com/gdamore/tcell/terminfo"
+)
+
+// StreamOptions describe the terminal at the other end of the streams of a UI
+// created with NewStreamUI.
+type StreamOptions struct {
+	// Term is the type of the terminal, as in $TERM, e.g. "xterm-256color".
+	// It determines the colors used. If empty, the terminal is assumed to be
+	// compatible with xterm.
+	Term string
+
+	// Size is the size of the terminal when the UI starts. If zero, 80x24 is
+	// assumed.
+	Size image.Point
+
+	// Resize receives the new size of the terminal whenever it's resized,
+	// e.g. from the window-change requests of an SSH session.
+	Resize <-chan image.Point
+}
+
+// newStreamUI returns a UI that reads from in and draws on the whole of the
+// terminal at the other end of out.
+func newStreamUI(root Widget, in io.Reader, out io.Writer, opts StreamOptions) (*tcellUI, error) {
+	colors, err := termColors(opts.Term)
+	if err != nil {
+		return nil, err
+	}
+
+	screen := &ttyScreen{
+		in:         in,
+		out:        out,
+		sizes:      opts.Resize,
+		fullscreen: true,
+	}
+	screen.setSize(opts.Size)
+
+	s := &ttySurface{
+		ANSISurface: NewANSISurface(screen, 0, 0, ANSIFull),
+		screen:      screen,
+	}
+	s.SetColors(colors)
+	screen.surface = s.ANSISurface
+
+	return newScreenUI(root, screen, s), nil
+}
+
+// termColors returns how many colors a type of terminal can show, or an error
+// if it doesn't understand ANSI escape sequences. Terminals missing from the
+// terminfo database are assumed to be compatible with xterm.
+func termColors(term string) (int, error) {
+	if term == "" {
+		return 256, nil
+	}
+	if term == "dumb" {
+		return 0, fmt.Errorf("tui: unsupported terminal type %q", term)
+	}
+
+	ti, err := terminfo.LookupTerminfo(term)
+	if err != nil {
+		return 256, nil
+	}
+	if !strings.HasPrefix(ti.SetCursor, "\x1b[") {
+		return 0, fmt.Errorf("tui: unsupported terminal type %q", term)
+	}
+	return ti.Colors, nil
+}
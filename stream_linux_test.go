@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"image"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamUI_PTY(t *testing.T) {
+	master, name := openPTY(t, 20, 5)
+	out := readPTY(master)
+
+	tty, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tty.Close()
+
+	a := NewEntry()
+	b := NewEntry()
+	root := NewVBox(a, b, NewSpacer())
+
+	resize := make(chan image.Point)
+	ui, err := NewStreamUI(root, tty, tty, StreamOptions{
+		Term:   "xterm-256color",
+		Size:   image.Pt(20, 5),
+		Resize: resize,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui.SetFocusChain(NewTreeFocusChain(root))
+
+	resized := make(chan image.Point, 1)
+	ui.OnResize(func(size image.Point) {
+		resized <- size
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ui.Run()
+	}()
+
+	waitFor(t, "first frame", func() bool {
+		return strings.Contains(out.String(), enableMouse)
+	})
+
+	// Type in the first entry, then click the second one and type in it.
+	master.Write([]byte("one\x1b[<0;2;2M\x1b[<0;2;2mtwo"))
+	waitFor(t, "typed text", func() bool {
+		var text string
+		ui.Update(func() {
+			text = a.Text() + "," + b.Text()
+		})
+		return text == "one,two"
+	})
+
+	resize <- image.Pt(30, 6)
+	select {
+	case got := <-resized:
+		if got != image.Pt(30, 6) {
+			t.Errorf("got = %v; want = %v", got, image.Pt(30, 6))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for resize")
+	}
+
+	ui.Quit()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, "terminal to be restored", func() bool {
+		return strings.HasSuffix(out.String(), exitAltScreen)
+	})
+}
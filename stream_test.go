@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"bytes"
+	"image"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a buffer that can be written and read concurrently.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitFor waits for a condition to become true.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStreamUI_Sessions(t *testing.T) {
+	type session struct {
+		in    *io.PipeWriter
+		out   *syncBuffer
+		entry *Entry
+		done  chan error
+	}
+
+	var sessions []*session
+	for i := 0; i < 2; i++ {
+		r, w := io.Pipe()
+		s := &session{in: w, out: &syncBuffer{}, entry: NewEntry(), done: make(chan error, 1)}
+		s.entry.SetFocused(true)
+
+		ui, err := NewStreamUI(s.entry, r, s.out, StreamOptions{Term: "xterm-256color", Size: image.Pt(10, 2)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			s.done <- ui.Run()
+		}()
+
+		sessions = append(sessions, s)
+	}
+
+	io.WriteString(sessions[0].in, "one")
+	io.WriteString(sessions[1].in, "two")
+
+	for i, want := range []string{"one", "two"} {
+		s := sessions[i]
+		waitFor(t, want, func() bool {
+			return strings.Contains(s.out.String(), want)
+		})
+
+		// The UI shuts down once the input is closed.
+		s.in.Close()
+		select {
+		case err := <-s.done:
+			if err != nil {
+				t.Error(err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("UI did not shut down")
+		}
+
+		got := s.out.String()
+		if !strings.HasPrefix(got, enterAltScreen) || !strings.HasSuffix(got, exitAltScreen) {
+			t.Errorf("expected output within the alternate screen: %q", got)
+		}
+		if other := []string{"two", "one"}[i]; strings.Contains(got, other) {
+			t.Errorf("unexpected %q in output: %q", other, got)
+		}
+	}
+}
+
+func TestStreamUI_StopsReading(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+
+	ui, err := newStreamUI(NewLabel(""), r, &syncBuffer{}, StreamOptions{Size: image.Pt(5, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- ui.Run()
+	}()
+	ui.Update(func() {})
+	ui.Quit()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// The read in progress when the UI shut down still completes, but the
+	// reader stops there.
+	io.WriteString(w, "a")
+	select {
+	case <-ui.screen.(*ttyScreen).reader.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("reader did not stop")
+	}
+
+	// The rest of the input is left to the caller.
+	go io.WriteString(w, "b")
+	b := make([]byte, 1)
+	if _, err := io.ReadFull(r, b); err != nil || string(b) != "b" {
+		t.Errorf("got = %q, %v; want = %q", b, err, "b")
+	}
+}
+
+func TestStreamUI_Suspend(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+
+	entry := NewEntry()
+	entry.SetFocused(true)
+
+	out := &syncBuffer{}
+	ui, err := NewStreamUI(entry, r, out, StreamOptions{Size: image.Pt(10, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go ui.Run()
+	defer ui.Quit()
+
+	// The input read by the reader stopped by Suspend isn't lost.
+	if err := ui.Suspend(func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "ab")
+
+	waitFor(t, "ab", func() bool {
+		var text string
+		ui.Update(func() {
+			text = entry.Text()
+		})
+		return text == "ab"
+	})
+}
+
+func TestTermColors(t *testing.T) {
+	for _, tt := range []struct {
+		term   string
+		colors int
+		err    bool
+	}{
+		{"", 256, false},
+		{"xterm-256color", 256, false},
+		{"xterm", 8, false},
+		{"unknown-term", 256, false},
+		{"dumb", 0, true},
+		{"vt52", 0, true},
+	} {
+		colors, err := termColors(tt.term)
+		if (err != nil) != tt.err {
+			t.Errorf("%q: unexpected error: %v", tt.term, err)
+			continue
+		}
+		if colors != tt.colors {
+			t.Errorf("%q: got = %d; want = %d", tt.term, colors, tt.colors)
+		}
+	}
+}
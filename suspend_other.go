@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package tui
+
+import "os"
+
+// Job control is only available on Unix.
+var (
+	jobControlSignals []os.Signal
+	suspendSignal     os.Signal
+)
+
+func stopProcess(c chan<- os.Signal) error {
+	return nil
+}
@@ -0,0 +1,28 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package tui
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// jobControlSignals are the signals used to stop and continue the process
+// from a shell.
+var jobControlSignals = []os.Signal{syscall.SIGTSTP, syscall.SIGCONT}
+
+// suspendSignal asks the process to stop. It's also sent when Ctrl+Z is
+// pressed.
+var suspendSignal os.Signal = syscall.SIGTSTP
+
+// stopProcess stops the process and returns once it has been continued. The
+// process stops the same way as when no SIGTSTP handler is installed, so that
+// the shell takes back control of the terminal.
+func stopProcess(c chan<- os.Signal) error {
+	signal.Reset(syscall.SIGTSTP)
+	defer signal.Notify(c, syscall.SIGTSTP)
+
+	return syscall.Kill(syscall.Getpid(), syscall.SIGTSTP)
+}
@@ -3,6 +3,7 @@ package tui
 import "image"
 
 var _ Widget = &Table{}
+var _ Focusable = &Table{}
 
 // Table is a widget that lays out widgets in a table.
 type Table struct {
@@ -89,7 +90,7 @@ func (t *Table) Draw(p *Painter) {
 						Min: image.Point{},
 						Max: size,
 					}, func(p *Painter) {
-						w.Draw(p)
+						p.DrawWidget(w)
 					})
 				}
 			})
@@ -97,32 +98,48 @@ func (t *Table) Draw(p *Painter) {
 	}
 }
 
+// Focusable returns whether the table can be focused.
+func (t *Table) Focusable() bool {
+	return t.IsEnabled()
+}
+
 // OnKeyEvent handles an event and propagates it to all children.
 func (t *Table) OnKeyEvent(ev KeyEvent) {
 	if !t.IsFocused() {
 		return
 	}
 
+	// Leave keys with modifiers to the keybindings.
+	if ev.Modifiers != ModNone {
+		return
+	}
+
 	switch ev.Key {
 	case KeyUp:
 		t.moveUp()
+		ev.Consume()
 	case KeyDown:
 		t.moveDown()
+		ev.Consume()
 	case KeyEnter:
 		if t.onItemActivated != nil {
 			t.onItemActivated(t)
+			ev.Consume()
+		}
+	case KeyRune:
+		switch ev.Rune {
+		case 'k':
+			t.moveUp()
+			ev.Consume()
+		case 'j':
+			t.moveDown()
+			ev.Consume()
 		}
-	}
-
-	switch ev.Rune {
-	case 'k':
-		t.moveUp()
-	case 'j':
-		t.moveDown()
 	}
 }
 
 func (t *Table) moveUp() {
+	t.MarkDirty()
 	if t.selected > 0 {
 		t.selected--
 	}
@@ -132,6 +149,7 @@ func (t *Table) moveUp() {
 }
 
 func (t *Table) moveDown() {
+	t.MarkDirty()
 	if t.selected < t.rows-1 {
 		t.selected++
 	}
@@ -142,6 +160,7 @@ func (t *Table) moveDown() {
 
 // SetSelected changes the currently selected item.
 func (t *Table) SetSelected(i int) {
+	t.MarkDirty()
 	t.selected = i
 }
 
@@ -160,6 +179,7 @@ func (t *Table) Select(i int) {
 
 // RemoveRow removes specific row from the table
 func (t *Table) RemoveRow(index int) {
+	t.MarkDirty()
 	t.Grid.RemoveRow(index)
 	if t.selected == index {
 		t.selected = -1
@@ -170,6 +190,7 @@ func (t *Table) RemoveRow(index int) {
 
 // RemoveRows removes all the rows added to the table.
 func (t *Table) RemoveRows() {
+	t.MarkDirty()
 	t.Grid.RemoveRows()
 	t.selected = -1
 }
@@ -65,6 +65,31 @@ func (s *TestSurface) Size() image.Point {
 	return s.size
 }
 
+// SetSize changes the size of the surface, as if the terminal was resized.
+func (s *TestSurface) SetSize(w, h int) {
+	s.size = image.Point{w, h}
+}
+
+// Cell returns the contents of a cell, or false if it hasn't been painted.
+func (s *TestSurface) Cell(x, y int) (rune, Style, bool) {
+	cell, ok := s.cells[image.Point{x, y}]
+	return cell.Rune, cell.Style, ok
+}
+
+// clone returns a copy of the surface.
+func (s *TestSurface) clone() *TestSurface {
+	c := &TestSurface{
+		cells:   make(map[image.Point]testCell, len(s.cells)),
+		cursor:  s.cursor,
+		size:    s.size,
+		emptyCh: s.emptyCh,
+	}
+	for p, cell := range s.cells {
+		c.cells[p] = cell
+	}
+	return c
+}
+
 // String returns the characters written to the TestSurface.
 func (s *TestSurface) String() string {
 	var buf bytes.Buffer
@@ -85,33 +110,26 @@ func (s *TestSurface) String() string {
 	return buf.String()
 }
 
-// FgColors renders the TestSurface's foreground colors, using the digits 0-7 for painted cells, and the empty character for unpainted cells.
+// FgColors renders the TestSurface's foreground colors, using the digits 0-9
+// and the letters a-z for the colors up to 35, '#' for any other color, and
+// the empty character for unpainted cells. Use the tuitest package to check
+// colors beyond those.
 func (s *TestSurface) FgColors() string {
-	var buf bytes.Buffer
-	buf.WriteRune('\n')
-	for j := 0; j < s.size.Y; j++ {
-		for i := 0; i < s.size.X; i++ {
-			if cell, ok := s.cells[image.Point{i, j}]; ok {
-				color := cell.Style.Fg
-				buf.WriteRune('0' + rune(color))
-			} else {
-				buf.WriteRune(s.emptyCh)
-			}
-		}
-		buf.WriteRune('\n')
-	}
-	return buf.String()
+	return s.colors(func(st Style) Color { return st.Fg })
 }
 
-// BgColors renders the TestSurface's background colors, using the digits 0-7 for painted cells, and the empty character for unpainted cells.
+// BgColors renders the TestSurface's background colors, like FgColors.
 func (s *TestSurface) BgColors() string {
+	return s.colors(func(st Style) Color { return st.Bg })
+}
+
+func (s *TestSurface) colors(color func(Style) Color) string {
 	var buf bytes.Buffer
 	buf.WriteRune('\n')
 	for j := 0; j < s.size.Y; j++ {
 		for i := 0; i < s.size.X; i++ {
 			if cell, ok := s.cells[image.Point{i, j}]; ok {
-				color := cell.Style.Bg
-				buf.WriteRune('0' + rune(color))
+				buf.WriteRune(colorDigit(color(cell.Style)))
 			} else {
 				buf.WriteRune(s.emptyCh)
 			}
@@ -121,6 +139,15 @@ func (s *TestSurface) BgColors() string {
 	return buf.String()
 }
 
+// colorDigit returns the character written for a color by FgColors and
+// BgColors.
+func colorDigit(c Color) rune {
+	if c < 0 || c >= 36 {
+		return '#'
+	}
+	return rune(strconv.FormatInt(int64(c), 36)[0])
+}
+
 // Decorations renders the TestSurface's decorations (Reverse, Bold, Underline) using a bitmask:
 //	Reverse: 1
 //	Bold: 2
@@ -0,0 +1,18 @@
+package tui
+
+import "testing"
+
+func TestTestSurface_Colors(t *testing.T) {
+	surface := NewTestSurface(5, 1)
+	for i, c := range []Color{ColorDefault, ColorYellow, Color(10), Color(35), Color(196)} {
+		surface.SetCell(i, 0, 'x', Style{Fg: c, Bg: c})
+	}
+
+	want := "\n08az#\n"
+	if got := surface.FgColors(); got != want {
+		t.Errorf("got = %q; want = %q", got, want)
+	}
+	if got := surface.BgColors(); got != want {
+		t.Errorf("got = %q; want = %q", got, want)
+	}
+}
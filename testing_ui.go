@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/gdamore/tcell"
+)
+
+var _ UI = &TestUI{}
+
+// TestUI is a UI that runs without a terminal. It paints on a TestSurface and
+// lets tests inject input, so that whole applications, including keybindings,
+// focus changes and Update callbacks, can be tested with go test.
+//
+// Run must be running in a separate goroutine while events are injected:
+//
+//	ui := tui.NewTestUI(root, 20, 5)
+//	go ui.Run()
+//	defer ui.Quit()
+//
+//	ui.Type("hello<Enter>")
+//	fmt.Println(ui.Snapshot())
+type TestUI struct {
+	*tcellUI
+
+	surface *TestSurface
+	sim     tcell.SimulationScreen
+}
+
+// NewTestUI returns a new TestUI with a root widget and a screen of the given
+// size.
+func NewTestUI(root Widget, w, h int) *TestUI {
+	sim := tcell.NewSimulationScreen("UTF-8")
+	sim.SetSize(w, h)
+
+	surface := NewTestSurface(w, h)
+
+	ui := &TestUI{
+		tcellUI: newScreenUI(root, sim, surface),
+		surface: surface,
+		sim:     sim,
+	}
+	ui.newScreen = func() (terminal, error) {
+		return sim, nil
+	}
+
+	return ui
+}
+
+// SendKey injects a key event, as if the key was pressed.
+func (ui *TestUI) SendKey(ev KeyEvent) {
+	ui.eventQueue <- ev
+}
+
+// SendMouse injects a mouse event. The position of the event is in screen
+// coordinates.
+func (ui *TestUI) SendMouse(ev MouseEvent) {
+	ui.eventQueue <- ev
+}
+
+// Paste injects text, as if it was pasted into the terminal.
+func (ui *TestUI) Paste(text string) {
+	ui.eventQueue <- PasteEvent{Text: text}
+}
+
+// Click injects a press and a release of the left mouse button at the given
+// screen coordinates.
+func (ui *TestUI) Click(x, y int) {
+	ui.SendMouse(MouseEvent{Pos: image.Pt(x, y), Action: MousePress, Button: MouseButtonLeft})
+	ui.SendMouse(MouseEvent{Pos: image.Pt(x, y), Action: MouseRelease, Button: MouseButtonLeft})
+}
+
+// Type injects a sequence of key presses. Characters are sent as they are,
+// while named keys are written within angle brackets, using the same names as
+// SetKeybinding, e.g. "hello<Enter><Tab><Ctrl+X>". Use "<<>" to type a
+// single '<'.
+func (ui *TestUI) Type(seq string) error {
+	evs, err := parseKeySequence(seq)
+	if err != nil {
+		return err
+	}
+	for _, ev := range evs {
+		ui.SendKey(ev)
+	}
+	return nil
+}
+
+// Resize changes the size of the screen, as if the terminal was resized.
+func (ui *TestUI) Resize(w, h int) {
+	ui.Update(func() {
+		ui.surface.SetSize(w, h)
+		ui.sim.SetSize(w, h)
+	})
+	ui.sendResize()
+}
+
+// sendResize sends a resize to the event loop, unless one is already waiting
+// to be handled, as the poll loop does. The new size is read from the screen
+// when painting.
+func (ui *TestUI) sendResize() {
+	if ui.queueResize() {
+		ui.eventQueue <- paintEvent{}
+	}
+}
+
+// WaitIdle blocks until the events injected so far, the functions passed to
+// Update and the functions they Post have all been run. The events are handed
+// to the UI one at a time, so an Update that runs after them sees them
+// handled. WaitIdle doesn't wait for timers that haven't fired yet, nor for
+// frames held back by the frame rate limit.
+func (ui *TestUI) WaitIdle() {
+	for {
+		var idle bool
+		ui.Update(func() {
+			idle = !ui.hasPosted()
+		})
+		if idle {
+			return
+		}
+	}
+}
+
+// hasPosted returns whether there are functions waiting to be run.
+func (ui *TestUI) hasPosted() bool {
+	ui.postMu.Lock()
+	defer ui.postMu.Unlock()
+	return len(ui.posted) > 0
+}
+
+// Snapshot waits for the UI to become idle and returns a copy of what is
+// currently drawn on the screen. A frame held back by the frame rate limit is
+// painted right away.
+func (ui *TestUI) Snapshot() *TestSurface {
+	ui.WaitIdle()
+
+	var s *TestSurface
+	ui.Update(func() {
+		if ui.needsPaint {
+			ui.paint()
+		}
+		s = ui.surface.clone()
+	})
+	return s
+}
+
+// parseKeySequence parses a sequence of key presses as accepted by
+// TestUI.Type.
+func parseKeySequence(seq string) ([]KeyEvent, error) {
+	var evs []KeyEvent
+
+	for len(seq) > 0 {
+		if strings.HasPrefix(seq, "<") && len(seq) > 2 {
+			// The key name is at least one character long, which lets
+			// "<<>" and "<>>" be used for the brackets themselves.
+			if end := strings.IndexRune(seq[2:], '>'); end >= 0 {
+				end += 2
+				ev, err := parseKey(seq[1:end])
+				if err != nil {
+					return nil, fmt.Errorf("invalid key sequence: %v", err)
+				}
+				evs = append(evs, ev)
+				seq = seq[end+1:]
+				continue
+			}
+		}
+
+		r := []rune(seq)[0]
+		seq = seq[len(string(r)):]
+
+		evs = append(evs, keyForRune(r))
+	}
+
+	return evs, nil
+}
@@ -0,0 +1,433 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// runTestUI runs a configured TestUI and returns a function that stops it.
+func runTestUI(t *testing.T, ui *TestUI) func() {
+	done := make(chan error, 1)
+	go func() {
+		done <- ui.Run()
+	}()
+
+	return func() {
+		ui.Quit()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestTestUI_Type(t *testing.T) {
+	user := NewEntry()
+	pass := NewEntry()
+
+	var submitted string
+	pass.OnSubmit(func(e *Entry) {
+		submitted = user.Text() + ":" + e.Text()
+	})
+
+	chain := &SimpleFocusChain{}
+	chain.Set(user, pass)
+
+	root := NewVBox(user, pass, NewSpacer())
+
+	ui := NewTestUI(root, 10, 3)
+	ui.SetFocusChain(chain)
+	defer runTestUI(t, ui)()
+
+	if err := ui.Type("john<Tab>secret<Enter>"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+john      
+secret    
+          
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+	if submitted != "john:secret" {
+		t.Errorf("got = %q; want = %q", submitted, "john:secret")
+	}
+}
+
+func TestTestUI_Keybinding(t *testing.T) {
+	l := NewLabel("")
+	ui := NewTestUI(l, 10, 1)
+
+	var n int
+	ui.SetKeybinding("Ctrl+X", func() {
+		n++
+		l.SetText("pressed")
+	})
+	defer runTestUI(t, ui)()
+
+	if err := ui.Type("x<Ctrl+X>"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+pressed...
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+	if n != 1 {
+		t.Errorf("got = %d; want = %d", n, 1)
+	}
+}
+
+func TestTestUI_UpdateAndResize(t *testing.T) {
+	l := NewLabel("foo")
+	ui := NewTestUI(l, 5, 1)
+	defer runTestUI(t, ui)()
+
+	ui.Update(func() {
+		l.SetText("bar")
+	})
+
+	ui.Resize(3, 2)
+
+	want := `
+bar
+...
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestTestUI_Click(t *testing.T) {
+	var clicked *Button
+
+	a := NewButton("a")
+	a.OnActivated(func(b *Button) { clicked = b })
+	b := NewButton("b")
+	b.OnActivated(func(b *Button) { clicked = b })
+
+	ui := NewTestUI(NewHBox(a, b), 2, 1)
+	defer runTestUI(t, ui)()
+
+	ui.Click(1, 0)
+	ui.WaitIdle()
+
+	if clicked != b {
+		t.Errorf("expected second button to be clicked")
+	}
+}
+
+func TestParseKeySequence(t *testing.T) {
+	got, err := parseKeySequence("a<Enter><<>\t<Alt+b>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []KeyEvent{
+		{Key: KeyRune, Rune: 'a'},
+		{Key: KeyEnter, Rune: rune(KeyEnter)},
+		{Key: KeyRune, Rune: '<'},
+		{Key: KeyTab, Rune: '\t'},
+		{Key: KeyRune, Rune: 'b', Modifiers: ModAlt},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got = %+v; want = %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got = %+v; want = %+v", got[i], want[i])
+		}
+	}
+
+	if _, err := parseKeySequence("<NoSuchKey>"); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestTestUI_Post(t *testing.T) {
+	l := NewLabel("")
+	ui := NewTestUI(l, 5, 1)
+
+	// Functions may be posted before the UI is running.
+	ui.Post(func() {
+		l.SetText(l.Text() + "a")
+	})
+
+	defer runTestUI(t, ui)()
+
+	ui.Update(func() {
+		// Posting from the UI goroutine doesn't block, and runs after the
+		// current callback.
+		ui.Post(func() {
+			l.SetText(l.Text() + "c")
+		})
+		l.SetText(l.Text() + "b")
+	})
+
+	want := `
+abc..
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestTestUI_NestedUpdate(t *testing.T) {
+	l := NewLabel("")
+	ui := NewTestUI(l, 5, 1)
+	ui.SetKeybinding("a", func() {
+		ui.Update(func() {
+			l.SetText("key")
+		})
+	})
+	defer runTestUI(t, ui)()
+
+	ui.Update(func() {
+		ui.Update(func() {
+			l.SetText("upd")
+		})
+	})
+	if l.Text() != "upd" {
+		t.Errorf("got = %q; want = %q", l.Text(), "upd")
+	}
+
+	if err := ui.Type("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+key..
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestTestUI_UpdateWhileBusy(t *testing.T) {
+	ui := NewTestUI(NewLabel(""), 5, 1)
+	defer runTestUI(t, ui)()
+
+	var got []string
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go ui.Update(func() {
+		close(started)
+		<-release
+		got = append(got, "first")
+	})
+	<-started
+
+	// An Update from another goroutine waits for the running callback
+	// instead of running right away.
+	done := make(chan struct{})
+	go func() {
+		ui.Update(func() {
+			got = append(got, "second")
+		})
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	<-done
+
+	ui.Update(func() {
+		if strings.Join(got, ",") != "first,second" {
+			t.Errorf("got = %v; want = %v", got, []string{"first", "second"})
+		}
+	})
+}
+
+func TestTestUI_Chords(t *testing.T) {
+	e := NewEntry()
+	e.SetFocused(true)
+
+	ui := NewTestUI(e, 10, 1)
+	ui.SetChordTimeout(time.Hour)
+
+	var got []string
+	ui.SetKeybinding("Ctrl+X Ctrl+S", func() { got = append(got, "save") })
+	ui.SetKeybinding("g g", func() { got = append(got, "top") })
+
+	var chords []string
+	ui.OnPendingChord(func(chord string) {
+		chords = append(chords, chord)
+	})
+
+	defer runTestUI(t, ui)()
+
+	if err := ui.CheckKeybindings(); err != nil {
+		t.Error(err)
+	}
+
+	if err := ui.Type("<Ctrl+X>"); err != nil {
+		t.Fatal(err)
+	}
+	ui.Update(func() {
+		if ui.PendingChord() != "Ctrl+X" {
+			t.Errorf("got = %q; want = %q", ui.PendingChord(), "Ctrl+X")
+		}
+	})
+
+	// Keys that are part of a chord don't reach the widgets. A key that
+	// cancels a chord is handled like any other key, while the cancelled
+	// keys are dropped. The entry consumes the keys typed into it.
+	if err := ui.Type("<Ctrl+S>a<Ctrl+X>bgg"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+abgg      
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+
+	ui.Update(func() {
+		e.SetFocused(false)
+	})
+	if err := ui.Type("gg"); err != nil {
+		t.Fatal(err)
+	}
+
+	ui.Update(func() {
+		if strings.Join(got, ",") != "save,top" {
+			t.Errorf("got = %v; want = %v", got, []string{"save", "top"})
+		}
+		wantChords := "Ctrl+X,,Ctrl+X,,g,"
+		if strings.Join(chords, ",") != wantChords {
+			t.Errorf("got = %q; want = %q", strings.Join(chords, ","), wantChords)
+		}
+	})
+}
+
+func TestTestUI_ChordTimeout(t *testing.T) {
+	ui := NewTestUI(NewLabel(""), 10, 1)
+	ui.SetChordTimeout(time.Millisecond)
+
+	ran := make(chan string, 2)
+	ui.SetKeybinding("g", func() { ran <- "g" })
+	ui.SetKeybinding("g g", func() { ran <- "g g" })
+
+	defer runTestUI(t, ui)()
+
+	if err := ui.CheckKeybindings(); err == nil {
+		t.Errorf("expected conflict")
+	}
+
+	// The shorter binding runs once the chord times out.
+	if err := ui.Type("g"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-ran:
+		if got != "g" {
+			t.Errorf("got = %q; want = %q", got, "g")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("chord did not time out")
+	}
+
+	ui.Update(func() {
+		if ui.PendingChord() != "" {
+			t.Errorf("got = %q; want = %q", ui.PendingChord(), "")
+		}
+	})
+}
+
+func TestTestUI_Propagation(t *testing.T) {
+	var got []string
+
+	l := NewLabel("")
+	l.SetFocused(true)
+
+	inner := &keyRecorder{Box: NewVBox(l), name: "inner", got: &got}
+	outer := &keyRecorder{Box: NewVBox(inner), name: "outer", got: &got, consume: 'o'}
+	other := &keyRecorder{Box: NewVBox(NewLabel("")), name: "other", got: &got}
+
+	e := NewEntry()
+
+	ui := NewTestUI(NewHBox(outer, other, e), 10, 1)
+	ui.SetKeybinding("q", func() { got = append(got, "binding") })
+	ui.SetKeybinding("o", func() { got = append(got, "binding") })
+	defer runTestUI(t, ui)()
+
+	if err := ui.Type("qo"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Focusing the entry keeps the keys it consumes from the bindings.
+	ui.Update(func() {
+		l.SetFocused(false)
+		e.SetFocused(true)
+	})
+	if err := ui.Type("q<Esc>"); err != nil {
+		t.Fatal(err)
+	}
+	ui.WaitIdle()
+
+	// Unfocused widgets don't get the keys, and the outer box keeps 'o'
+	// from the binding.
+	want := "inner:q,outer:q,binding,inner:o,outer:o"
+	ui.Update(func() {
+		if strings.Join(got, ",") != want {
+			t.Errorf("got = %v; want = %v", strings.Join(got, ","), want)
+		}
+		if e.Text() != "q" {
+			t.Errorf("got = %q; want = %q", e.Text(), "q")
+		}
+	})
+}
+
+// keyRecorder is a Box that records the key events it receives, and consumes
+// the given rune.
+type keyRecorder struct {
+	*Box
+
+	name    string
+	got     *[]string
+	consume rune
+}
+
+func (r *keyRecorder) OnKeyEvent(ev KeyEvent) {
+	*r.got = append(*r.got, r.name+":"+ev.Name())
+	if ev.Rune == r.consume {
+		ev.Consume()
+	}
+}
+
+// opaque draws a widget without implementing Container, like a container
+// from another package might. It doesn't forward key events.
+type opaque struct {
+	Widget
+}
+
+func (o *opaque) OnKeyEvent(ev KeyEvent) {}
+func (o *opaque) IsFocused() bool        { return false }
+
+func TestTestUI_PropagationOutsideContainers(t *testing.T) {
+	e := NewEntry()
+
+	chain := &SimpleFocusChain{}
+	chain.Set(e)
+
+	ui := NewTestUI(NewVBox(&opaque{e}), 10, 1)
+	ui.SetFocusChain(chain)
+	defer runTestUI(t, ui)()
+
+	// The focused entry still gets the keys, even though it can't be
+	// found through the containers.
+	if err := ui.Type("hi"); err != nil {
+		t.Fatal(err)
+	}
+	ui.Update(func() {
+		if e.Text() != "hi" {
+			t.Errorf("got = %q; want = %q", e.Text(), "hi")
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 )
 
 var _ Widget = &TextEdit{}
+var _ Focusable = &TextEdit{}
 
 // TextEdit is a multi-line text editor.
 type TextEdit struct {
@@ -19,7 +20,9 @@ type TextEdit struct {
 
 // NewTextEdit returns a new TextEdit.
 func NewTextEdit() *TextEdit {
-	return &TextEdit{}
+	e := &TextEdit{}
+	e.trackDirtyFor(e)
+	return e
 }
 
 // Draw draws the entry.
@@ -56,12 +59,19 @@ func (e *TextEdit) SizeHint() image.Point {
 	return image.Point{max, e.text.heightForWidth(max)}
 }
 
+// Focusable returns whether the editor can be focused.
+func (e *TextEdit) Focusable() bool {
+	return e.IsEnabled()
+}
+
 // OnKeyEvent handles key events.
 func (e *TextEdit) OnKeyEvent(ev KeyEvent) {
 	if !e.IsFocused() {
 		return
 	}
 
+	e.MarkDirty()
+
 	screenWidth := e.Size().X
 	e.text.SetMaxWidth(screenWidth)
 
@@ -109,10 +119,19 @@ func (e *TextEdit) OnKeyEvent(ev KeyEvent) {
 			}
 		case KeyCtrlK:
 			e.text.Kill()
+		default:
+			return
 		}
+		ev.Consume()
 		return
 	}
 
+	// Leave shortcuts like Alt+X to the keybindings.
+	if ev.Modifiers&(ModAlt|ModMeta) != 0 {
+		return
+	}
+	ev.Consume()
+
 	e.text.WriteRune(ev.Rune)
 	if e.text.CursorPos().X >= screenWidth {
 		e.offset++
@@ -122,6 +141,24 @@ func (e *TextEdit) OnKeyEvent(ev KeyEvent) {
 	}
 }
 
+// OnPasteEvent inserts pasted text at the cursor.
+func (e *TextEdit) OnPasteEvent(ev PasteEvent) {
+	if !e.IsFocused() {
+		return
+	}
+
+	e.MarkDirty()
+
+	e.text.SetMaxWidth(e.Size().X)
+
+	text := strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(ev.Text)
+	e.text.WriteRunes([]rune(text))
+
+	if e.onTextChange != nil {
+		e.onTextChange(e)
+	}
+}
+
 // OnTextChanged sets a function to be run whenever the text content of the
 // widget has been changed.
 func (e *TextEdit) OnTextChanged(fn func(entry *TextEdit)) {
@@ -130,6 +167,7 @@ func (e *TextEdit) OnTextChanged(fn func(entry *TextEdit)) {
 
 // SetText sets the text content of the entry.
 func (e *TextEdit) SetText(text string) {
+	e.MarkDirty()
 	e.text.Set([]rune(text))
 }
 
@@ -140,6 +178,7 @@ func (e *TextEdit) Text() string {
 
 // SetWordWrap sets whether the text should wrap or not.
 func (e *TextEdit) SetWordWrap(enabled bool) {
+	e.MarkDirty()
 	e.text.wordwrap = enabled
 }
 
@@ -50,3 +50,23 @@ func TestTextEdit_Draw(t *testing.T) {
 		})
 	}
 }
+
+func TestTextEdit_OnPasteEvent(t *testing.T) {
+	e := NewTextEdit()
+	e.SetFocused(true)
+	e.SetText("ad")
+	e.Resize(image.Pt(10, 3))
+
+	var changes int
+	e.OnTextChanged(func(*TextEdit) { changes++ })
+
+	e.OnKeyEvent(KeyEvent{Key: KeyLeft})
+	e.OnPasteEvent(PasteEvent{Text: "b\r\nc"})
+
+	if e.Text() != "ab\ncd" {
+		t.Errorf("got = %q; want = %q", e.Text(), "ab\ncd")
+	}
+	if changes != 1 {
+		t.Errorf("got = %d; want = %d", changes, 1)
+	}
+}
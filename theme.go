@@ -35,6 +35,7 @@ type Style struct {
 	Reverse   Decoration
 	Bold      Decoration
 	Underline Decoration
+	Dim       Decoration
 }
 
 // mergeIn returns the receiver Style, with any changes in delta applied.
@@ -55,12 +56,19 @@ func (s Style) mergeIn(delta Style) Style {
 	if delta.Underline != DecorationInherit {
 		result.Underline = delta.Underline
 	}
+	if delta.Dim != DecorationInherit {
+		result.Dim = delta.Dim
+	}
 	return result
 }
 
 // Theme defines the styles for a set of identifiers.
 type Theme struct {
 	styles map[string]Style
+
+	// rev is increased whenever a style changes, so that the painter can
+	// tell that widgets drawn with the previous styles must be redrawn.
+	rev int
 }
 
 // DefaultTheme is a theme with reasonable defaults.
@@ -69,6 +77,9 @@ var DefaultTheme = &Theme{
 		"list.item.selected":  {Reverse: DecorationOn},
 		"table.cell.selected": {Reverse: DecorationOn},
 		"button.focused":      {Reverse: DecorationOn},
+		"layer.dim":           {Dim: DecorationOn},
+		"menu.item.selected":  {Reverse: DecorationOn},
+		"menu.item.disabled":  {Dim: DecorationOn},
 	},
 }
 
@@ -82,6 +93,7 @@ func NewTheme() *Theme {
 // SetStyle sets a style for a given identifier.
 func (p *Theme) SetStyle(n string, i Style) {
 	p.styles[n] = i
+	p.rev++
 }
 
 // Style returns the style associated with an identifier.
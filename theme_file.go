@@ -0,0 +1,583 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ThemeError is returned when a theme file can't be loaded.
+type ThemeError struct {
+	// Line is the line of the file where the error was found.
+	Line int
+	Msg  string
+}
+
+func (e *ThemeError) Error() string {
+	return fmt.Sprintf("tui: theme: line %d: %s", e.Line, e.Msg)
+}
+
+func themeErrorf(line int, format string, args ...interface{}) error {
+	return &ThemeError{Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// colorNames holds the names of the named colors in theme files.
+var colorNames = map[Color]string{
+	ColorDefault: "default",
+	ColorBlack:   "black",
+	ColorWhite:   "white",
+	ColorRed:     "red",
+	ColorGreen:   "green",
+	ColorBlue:    "blue",
+	ColorCyan:    "cyan",
+	ColorMagenta: "magenta",
+	ColorYellow:  "yellow",
+}
+
+// LoadTheme reads a theme from a JSON or TOML file. The format is detected
+// from the contents: a JSON theme is an object holding a style for each name,
+// while a TOML theme has a table for each style:
+//
+//	{
+//	  "label.warning": {"fg": "red", "bold": true},
+//	  "list.item.selected": {"fg": "#ffffff", "bg": 24}
+//	}
+//
+//	[label.warning]
+//	fg = "red"
+//	bold = true
+//
+//	[list.item.selected]
+//	fg = "#ffffff"
+//	bg = 24
+//
+// A style has the following attributes, which can all be left out:
+//
+//	fg, bg      a color: one of default, black, white, red, green, blue,
+//	            cyan, magenta and yellow, a hex color such as "#ff8700", or
+//	            an index of the 256-color palette
+//	bold, dim, underline, reverse
+//	            true or false; a decoration that is left out is inherited
+//
+// Hex colors are replaced by the closest color of the 256-color palette.
+// Only the TOML needed for themes is supported: tables with dotted or quoted
+// names, and keys set to strings, integers or booleans.
+//
+// Errors are returned as a *ThemeError, with the line where they were found.
+func LoadTheme(r io.Reader) (*Theme, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	t := NewTheme()
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		err = loadJSONTheme(t, data)
+	} else {
+		err = loadTOMLTheme(t, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Save writes the theme as JSON, in the format read by LoadTheme. The styles
+// are sorted by name.
+func (p *Theme) Save(w io.Writer) error {
+	var buf bytes.Buffer
+
+	buf.WriteString("{")
+	for i, name := range p.names() {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, _ := json.Marshal(name)
+		fmt.Fprintf(&buf, "\n  %s: {", key)
+		for j, attr := range styleAttrs(p.styles[name]) {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "%q: %s", attr.name, attr.value)
+		}
+		buf.WriteString("}")
+	}
+	buf.WriteString("\n}\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// SaveTOML writes the theme as TOML, in the format read by LoadTheme. The
+// styles are sorted by name.
+func (p *Theme) SaveTOML(w io.Writer) error {
+	var buf bytes.Buffer
+
+	for i, name := range p.names() {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "[%s]\n", tomlKey(name))
+		for _, attr := range styleAttrs(p.styles[name]) {
+			fmt.Fprintf(&buf, "%s = %s\n", attr.name, attr.value)
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// names returns the names of the styles, sorted.
+func (p *Theme) names() []string {
+	names := make([]string, 0, len(p.styles))
+	for name := range p.styles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// styleAttr is an attribute of a style in a theme file, with its value written
+// as both JSON and TOML would.
+type styleAttr struct {
+	name, value string
+}
+
+// styleAttrs returns the attributes of a style that aren't inherited.
+func styleAttrs(s Style) []styleAttr {
+	var attrs []styleAttr
+	for _, c := range []struct {
+		name  string
+		color Color
+	}{
+		{"fg", s.Fg},
+		{"bg", s.Bg},
+	} {
+		if c.color == ColorDefault {
+			continue
+		}
+		if name, ok := colorNames[c.color]; ok {
+			attrs = append(attrs, styleAttr{c.name, strconv.Quote(name)})
+		} else {
+			attrs = append(attrs, styleAttr{c.name, strconv.Itoa(int(c.color))})
+		}
+	}
+	for _, d := range []struct {
+		name string
+		dec  Decoration
+	}{
+		{"bold", s.Bold},
+		{"dim", s.Dim},
+		{"underline", s.Underline},
+		{"reverse", s.Reverse},
+	} {
+		if d.dec != DecorationInherit {
+			attrs = append(attrs, styleAttr{d.name, strconv.FormatBool(d.dec == DecorationOn)})
+		}
+	}
+	return attrs
+}
+
+// setStyleAttr sets an attribute of a style, read from a theme file. The value
+// is a string, an int64 or a bool.
+func setStyleAttr(s *Style, line int, name string, value interface{}) error {
+	switch name {
+	case "fg", "bg":
+		c, err := parseThemeColor(value)
+		if err != nil {
+			return themeErrorf(line, "%s: %v", name, err)
+		}
+		if name == "fg" {
+			s.Fg = c
+		} else {
+			s.Bg = c
+		}
+		return nil
+	case "bold", "dim", "underline", "reverse":
+		on, ok := value.(bool)
+		if !ok {
+			return themeErrorf(line, "%s: got %s; want true or false", name, describeValue(value))
+		}
+		d := DecorationOff
+		if on {
+			d = DecorationOn
+		}
+		switch name {
+		case "bold":
+			s.Bold = d
+		case "dim":
+			s.Dim = d
+		case "underline":
+			s.Underline = d
+		case "reverse":
+			s.Reverse = d
+		}
+		return nil
+	}
+	return themeErrorf(line, "unknown attribute %q", name)
+}
+
+// parseThemeColor parses a color name, a hex color or a palette index.
+func parseThemeColor(value interface{}) (Color, error) {
+	switch v := value.(type) {
+	case int64:
+		if v < 0 || v > 255 {
+			return 0, fmt.Errorf("palette index %d out of range", v)
+		}
+		return paletteColor(int(v)), nil
+	case string:
+		for c, name := range colorNames {
+			if strings.EqualFold(v, name) {
+				return c, nil
+			}
+		}
+		if len(v) == 7 && v[0] == '#' {
+			if rgb, err := strconv.ParseUint(v[1:], 16, 32); err == nil {
+				return nearestColor(int(rgb>>16), int(rgb>>8&0xff), int(rgb&0xff)), nil
+			}
+		}
+		return 0, fmt.Errorf("unknown color %q", v)
+	}
+	return 0, fmt.Errorf("got %s; want a color", describeValue(value))
+}
+
+// paletteColor returns the color for an index of the 256-color palette. The
+// first colors of the palette that have no Color of their own are replaced by
+// the closest color.
+func paletteColor(i int) Color {
+	if i > int(ColorYellow) {
+		return Color(i)
+	}
+	for c := ColorBlack; c <= ColorYellow; c++ {
+		if j, _ := ansiColor(c); j == i {
+			return c
+		}
+	}
+	var r, g, b int
+	fmt.Sscanf(paletteHex(i), "#%02x%02x%02x", &r, &g, &b)
+	return nearestColor(r, g, b)
+}
+
+// nearestColor returns the color closest to an RGB color, out of the named
+// colors and the palette colors after them.
+func nearestColor(r, g, b int) Color {
+	best, bestDist := ColorDefault, -1
+	for c := ColorBlack; c < 256; c++ {
+		i, _ := ansiColor(c)
+		var cr, cg, cb int
+		fmt.Sscanf(paletteHex(i), "#%02x%02x%02x", &cr, &cg, &cb)
+		dist := (r-cr)*(r-cr) + (g-cg)*(g-cg) + (b-cb)*(b-cb)
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = c, dist
+		}
+	}
+	return best
+}
+
+// describeValue describes a value read from a theme file in an error.
+func describeValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case nil:
+		return "null"
+	}
+	return fmt.Sprint(v)
+}
+
+// loadJSONTheme reads the styles of a JSON theme into t.
+func loadJSONTheme(t *Theme, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	// line returns the line of the last token read.
+	line := func() int {
+		off := minOf(int(dec.InputOffset()), len(data))
+		return 1 + bytes.Count(data[:off], []byte("\n"))
+	}
+
+	token := func() (json.Token, error) {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, themeErrorf(line(), "unexpected end of file")
+		}
+		if err, ok := err.(*json.SyntaxError); ok {
+			off := minOf(int(err.Offset), len(data))
+			return nil, themeErrorf(1+bytes.Count(data[:off], []byte("\n")), "%v", err)
+		}
+		return tok, err
+	}
+
+	expect := func(want json.Delim, what string) error {
+		tok, err := token()
+		if err != nil {
+			return err
+		}
+		if tok != want {
+			return themeErrorf(line(), "got %s; want %s", describeValue(tok), what)
+		}
+		return nil
+	}
+
+	if err := expect('{', "an object of styles"); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := token()
+		if err != nil {
+			return err
+		}
+		name := tok.(string)
+		if t.HasStyle(name) {
+			return themeErrorf(line(), "style %q is defined more than once", name)
+		}
+
+		if err := expect('{', "a style"); err != nil {
+			return err
+		}
+		var s Style
+		for dec.More() {
+			tok, err := token()
+			if err != nil {
+				return err
+			}
+			attr := tok.(string)
+
+			if tok, err = token(); err != nil {
+				return err
+			}
+			value := interface{}(tok)
+			if n, ok := tok.(json.Number); ok {
+				i, err := n.Int64()
+				if err != nil {
+					return themeErrorf(line(), "%s: got %s; want an integer", attr, n)
+				}
+				value = i
+			}
+			if err := setStyleAttr(&s, line(), attr, value); err != nil {
+				return err
+			}
+		}
+		if err := expect('}', "the end of the style"); err != nil {
+			return err
+		}
+		t.SetStyle(name, s)
+	}
+	return expect('}', "the end of the styles")
+}
+
+// loadTOMLTheme reads the styles of a TOML theme into t.
+func loadTOMLTheme(t *Theme, data []byte) error {
+	var (
+		s       *Style
+		name    string
+		defined = make(map[string]bool)
+	)
+	flush := func() {
+		if s != nil {
+			t.SetStyle(name, *s)
+		}
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		l := strings.TrimSpace(sc.Text())
+		if l == "" || l[0] == '#' {
+			continue
+		}
+
+		if l[0] == '[' {
+			parts, rest, err := parseTOMLKey(l[1:])
+			if err != nil {
+				return themeErrorf(n, "%v", err)
+			}
+			if !strings.HasPrefix(rest, "]") || !isTOMLComment(rest[1:]) {
+				return themeErrorf(n, "invalid table header: %s", l)
+			}
+			flush()
+			name = strings.Join(parts, ".")
+			if defined[name] {
+				return themeErrorf(n, "style %q is defined more than once", name)
+			}
+			defined[name] = true
+			s = &Style{}
+			continue
+		}
+
+		parts, rest, err := parseTOMLKey(l)
+		if err != nil {
+			return themeErrorf(n, "%v", err)
+		}
+		if !strings.HasPrefix(rest, "=") {
+			return themeErrorf(n, "expected a key and a value: %s", l)
+		}
+		value, rest, err := parseTOMLValue(strings.TrimSpace(rest[1:]))
+		if err != nil {
+			return themeErrorf(n, "%v", err)
+		}
+		if !isTOMLComment(rest) {
+			return themeErrorf(n, "unexpected %q after the value", rest)
+		}
+		attr := strings.Join(parts, ".")
+		if s == nil {
+			return themeErrorf(n, "attribute %q outside of a style", attr)
+		}
+		if err := setStyleAttr(s, n, attr, value); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	flush()
+	return nil
+}
+
+// isTOMLComment returns whether the rest of a line is blank or a comment.
+func isTOMLComment(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || s[0] == '#'
+}
+
+// parseTOMLKey parses a dotted key, made of bare and quoted keys, and returns
+// its parts and the rest of the line.
+func parseTOMLKey(s string) ([]string, string, error) {
+	var parts []string
+	for {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return nil, "", fmt.Errorf("missing key")
+		}
+
+		var part string
+		if s[0] == '"' || s[0] == '\'' {
+			v, rest, err := parseTOMLString(s)
+			if err != nil {
+				return nil, "", err
+			}
+			part, s = v, rest
+		} else {
+			end := strings.IndexFunc(s, func(r rune) bool {
+				return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-')
+			})
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, "", fmt.Errorf("invalid key: %s", s)
+			}
+			part, s = s[:end], s[end:]
+		}
+		parts = append(parts, part)
+
+		s = strings.TrimSpace(s)
+		if !strings.HasPrefix(s, ".") {
+			return parts, s, nil
+		}
+		s = s[1:]
+	}
+}
+
+// parseTOMLValue parses a string, an integer or a boolean, and returns it as a
+// string, an int64 or a bool, along with the rest of the line.
+func parseTOMLValue(s string) (interface{}, string, error) {
+	if s == "" {
+		return nil, "", fmt.Errorf("missing value")
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		return parseTOMLString(s)
+	}
+
+	end := strings.IndexAny(s, " \t#")
+	if end < 0 {
+		end = len(s)
+	}
+	v, rest := s[:end], s[end:]
+
+	switch v {
+	case "true":
+		return true, rest, nil
+	case "false":
+		return false, rest, nil
+	}
+	i, err := parseTOMLInt(v)
+	if err != nil {
+		return nil, "", err
+	}
+	return i, rest, nil
+}
+
+// parseTOMLInt parses a decimal integer, or a hexadecimal one prefixed with
+// 0x. As in TOML, a decimal integer with leading zeros is an error rather than
+// an octal one.
+func parseTOMLInt(v string) (int64, error) {
+	digits := strings.Replace(v, "_", "", -1)
+
+	base := 10
+	if strings.HasPrefix(digits, "0x") {
+		base = 16
+		digits = digits[2:]
+	} else {
+		unsigned := strings.TrimLeft(digits, "+-")
+		if len(unsigned) > 1 && unsigned[0] == '0' && strings.Trim(unsigned, "0123456789") == "" {
+			return 0, fmt.Errorf("leading zeros are not allowed: %s", v)
+		}
+	}
+
+	i, err := strconv.ParseInt(digits, base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unsupported value: %s", v)
+	}
+	return i, nil
+}
+
+// parseTOMLString parses a basic or literal string, and returns the rest of
+// the line.
+func parseTOMLString(s string) (string, string, error) {
+	if s[0] == '\'' {
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated string: %s", s)
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			v, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid string: %s", s[:i+1])
+			}
+			return v, s[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string: %s", s)
+}
+
+// tomlKey returns a style name as a dotted TOML key, quoting the parts that
+// aren't bare keys.
+func tomlKey(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		bare := p != ""
+		for _, r := range p {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+				bare = false
+			}
+		}
+		if !bare {
+			parts[i] = strconv.Quote(p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
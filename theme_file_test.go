@@ -0,0 +1,188 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const jsonTheme = `{
+  "label.warning": {"fg": "red", "bold": true, "underline": false},
+  "list.item.selected": {"fg": "#ffffff", "bg": 24, "reverse": true},
+  "status": {"bg": "#5f87d7", "dim": true}
+}
+`
+
+const tomlTheme = `# Colors of the mail client.
+[label.warning]
+fg = "red"
+bold = true
+underline = false
+
+[list.item."selected"]  # Quoted keys are joined too.
+fg = '#ffffff'
+bg = 24
+reverse = true
+
+[status]
+bg = "#5f87d7"
+dim = true
+`
+
+func TestLoadTheme(t *testing.T) {
+	want := map[string]Style{
+		"label.warning":      {Fg: ColorRed, Bold: DecorationOn, Underline: DecorationOff},
+		"list.item.selected": {Fg: ColorWhite, Bg: Color(24), Reverse: DecorationOn},
+		"status":             {Bg: Color(68), Dim: DecorationOn},
+	}
+
+	for _, in := range []string{jsonTheme, tomlTheme} {
+		theme, err := LoadTheme(strings.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(theme.styles) != len(want) {
+			t.Errorf("got %d styles; want %d", len(theme.styles), len(want))
+		}
+		for name, style := range want {
+			if got := theme.Style(name); got != style {
+				t.Errorf("%s: got = %+v; want = %+v", name, got, style)
+			}
+		}
+	}
+}
+
+func TestTheme_Save(t *testing.T) {
+	theme := NewTheme()
+	theme.SetStyle("label.warning", Style{Fg: ColorRed, Bold: DecorationOn, Underline: DecorationOff})
+	theme.SetStyle("list.item.selected", Style{Bg: Color(24), Reverse: DecorationOn})
+	theme.SetStyle("odd name", Style{})
+
+	var buf bytes.Buffer
+	if err := theme.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "label.warning": {"fg": "red", "bold": true, "underline": false},
+  "list.item.selected": {"bg": 24, "reverse": true},
+  "odd name": {}
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got = \n%s\n\nwant = \n%s", got, want)
+	}
+
+	var tbuf bytes.Buffer
+	if err := theme.SaveTOML(&tbuf); err != nil {
+		t.Fatal(err)
+	}
+	want = `[label.warning]
+fg = "red"
+bold = true
+underline = false
+
+[list.item.selected]
+bg = 24
+reverse = true
+
+["odd name"]
+`
+	if got := tbuf.String(); got != want {
+		t.Errorf("got = \n%s\n\nwant = \n%s", got, want)
+	}
+
+	// Both load back into the same theme.
+	for _, b := range []*bytes.Buffer{&buf, &tbuf} {
+		loaded, err := LoadTheme(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, style := range theme.styles {
+			if got := loaded.Style(name); got != style || !loaded.HasStyle(name) {
+				t.Errorf("%s: got = %+v; want = %+v", name, got, style)
+			}
+		}
+	}
+}
+
+func TestLoadTheme_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want string
+	}{
+		{"{\n  \"label\": {\"fg\": \"rde\"}\n}", `tui: theme: line 2: fg: unknown color "rde"`},
+		{"{\n  \"label\": {\n    \"blod\": true\n  }\n}", `tui: theme: line 3: unknown attribute "blod"`},
+		{"{\n  \"label\": {\"bold\": \"yes\"}\n}", `tui: theme: line 2: bold: got "yes"; want true or false`},
+		{"{\n  \"label\": {\"bg\": 256}\n}", `tui: theme: line 2: bg: palette index 256 out of range`},
+		{"{\n  \"label\": {\"bg\": 1.5}\n}", `tui: theme: line 2: bg: got 1.5; want an integer`},
+		{"{\n  \"a\": {},\n  \"a\": {}\n}", `tui: theme: line 3: style "a" is defined more than once`},
+		{"{\n  \"label\": {\"fg\": \"red\",}\n}", `tui: theme: line 2: invalid character ',' looking for beginning of value`},
+		{"{\n  \"label\": {}\n", `tui: theme: line 3: unexpected end of JSON input`},
+		{"[label]\nfg = \"#12345\"\n", `tui: theme: line 2: fg: unknown color "#12345"`},
+		{"\n[label]\nbold = 1\n", `tui: theme: line 3: bold: got 1; want true or false`},
+		{"[label]\nfg = red\n", `tui: theme: line 2: unsupported value: red`},
+		{"[label]\nbold = true\nbg = 010\n", `tui: theme: line 3: leading zeros are not allowed: 010`},
+		{"[label]\nbg = 0x\n", `tui: theme: line 2: unsupported value: 0x`},
+		{"[label]\nbg = 0o17\n", `tui: theme: line 2: unsupported value: 0o17`},
+		{"[label]\nfg = \"red\" bold = true\n", `tui: theme: line 2: unexpected " bold = true" after the value`},
+		{"fg = \"red\"\n", `tui: theme: line 1: attribute "fg" outside of a style`},
+		{"[label\n", `tui: theme: line 1: invalid table header: [label`},
+		{"[a]\n[b]\n[a]\n", `tui: theme: line 3: style "a" is defined more than once`},
+		{"[a]\ncolor = \"red\"\n", `tui: theme: line 2: unknown attribute "color"`},
+	} {
+		_, err := LoadTheme(strings.NewReader(tt.in))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%q: got = %v; want = %s", tt.in, err, tt.want)
+		}
+		if _, ok := err.(*ThemeError); !ok {
+			t.Errorf("%q: got %T; want *ThemeError", tt.in, err)
+		}
+	}
+}
+
+func TestParseThemeColor(t *testing.T) {
+	for _, tt := range []struct {
+		in   interface{}
+		want Color
+	}{
+		{"default", ColorDefault},
+		{"Cyan", ColorCyan},
+		{"#ff0000", ColorRed},
+		{"#ff8800", Color(208)},
+		{"#000000", ColorBlack},
+		{int64(2), ColorGreen},
+		{int64(16), Color(16)},
+		// Palette colors without a Color of their own are replaced.
+		{int64(1), Color(160)},
+	} {
+		got, err := parseThemeColor(tt.in)
+		if err != nil {
+			t.Errorf("%v: %v", tt.in, err)
+		} else if got != tt.want {
+			t.Errorf("%v: got = %d; want = %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseTOMLInt(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want int64
+	}{
+		{"0", 0},
+		{"10", 10},
+		{"+10", 10},
+		{"1_000", 1000},
+		{"0x1f", 31},
+		{"0xFF", 255},
+	} {
+		got, err := parseTOMLInt(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got = %d; want = %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxFrameRate is the maximum number of frames per second drawn by a
+// UI, unless changed with SetMaxFrameRate.
+const DefaultMaxFrameRate = 60
+
+// Timer is a handle to a function scheduled with AfterFunc or Every.
+type Timer struct {
+	stop    chan struct{}
+	stopped int32
+}
+
+func newTimer() *Timer {
+	return &Timer{
+		stop: make(chan struct{}),
+	}
+}
+
+// Stop cancels the timer. When called from the UI goroutine, e.g. from within
+// the timer function itself, the function is guaranteed not to be called
+// again. Stopping a timer more than once has no effect.
+func (t *Timer) Stop() {
+	if atomic.CompareAndSwapInt32(&t.stopped, 0, 1) {
+		close(t.stop)
+	}
+}
+
+func (t *Timer) isStopped() bool {
+	return atomic.LoadInt32(&t.stopped) == 1
+}
+
+// runTimer waits for the timer to fire and sends fn to be called in the UI
+// goroutine, until the timer is stopped or the UI has shut down. If repeat is
+// false, the timer fires only once. Ticks that happen while the UI is busy are
+// dropped.
+func runTimer(t *Timer, d time.Duration, repeat bool, fn func(), queue chan<- event, done <-chan struct{}) {
+	var tick <-chan time.Time
+	if repeat {
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		tick = ticker.C
+	} else {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		tick = timer.C
+	}
+
+	ev := callbackEvent{func() {
+		if !t.isStopped() {
+			fn()
+		}
+	}}
+
+	for {
+		select {
+		case <-tick:
+		case <-t.stop:
+			return
+		case <-done:
+			return
+		}
+
+		select {
+		case queue <- ev:
+		case <-t.stop:
+			return
+		case <-done:
+			return
+		}
+
+		if !repeat {
+			return
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUI_AfterFunc(t *testing.T) {
+	l := NewLabel("waiting")
+	ui := NewTestUI(l, 7, 1)
+	defer runTestUI(t, ui)()
+
+	fired := make(chan struct{})
+	ui.AfterFunc(time.Millisecond, func() {
+		l.SetText("done")
+		close(fired)
+	})
+
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("timer did not fire")
+	}
+
+	// The cells painted by the previous text are cleared.
+	want := `
+done   
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestUI_AfterFunc_Stop(t *testing.T) {
+	ui := NewTestUI(NewLabel(""), 1, 1)
+	defer runTestUI(t, ui)()
+
+	timer := ui.AfterFunc(10*time.Millisecond, func() {
+		t.Error("stopped timer fired")
+	})
+	timer.Stop()
+	timer.Stop()
+
+	time.Sleep(20 * time.Millisecond)
+	ui.WaitIdle()
+}
+
+func TestUI_Every(t *testing.T) {
+	ui := NewTestUI(NewLabel(""), 1, 1)
+	defer runTestUI(t, ui)()
+
+	var (
+		n     int
+		timer *Timer
+	)
+	stopped := make(chan struct{})
+	ui.Update(func() {
+		timer = ui.Every(time.Millisecond, func() {
+			n++
+			if n == 3 {
+				timer.Stop()
+				close(stopped)
+			}
+		})
+	})
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("ticker did not fire")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	ui.Update(func() {
+		if n != 3 {
+			t.Errorf("got = %d; want = %d", n, 3)
+		}
+	})
+}
+
+func TestUI_RequestFrame(t *testing.T) {
+	ui := NewTestUI(NewLabel(""), 1, 1)
+	defer runTestUI(t, ui)()
+
+	frames := make(chan time.Time, 3)
+
+	var animate func(time.Time)
+	animate = func(now time.Time) {
+		frames <- now
+		if len(frames) < 2 {
+			ui.RequestFrame(animate)
+		}
+	}
+
+	// Callbacks requested for the same frame share the frame time.
+	ui.RequestFrame(animate)
+	ui.RequestFrame(func(now time.Time) {
+		frames <- now
+	})
+
+	var got []time.Time
+	for len(got) < 3 {
+		select {
+		case now := <-frames:
+			got = append(got, now)
+		case <-time.After(time.Second):
+			t.Fatalf("got %d frames; want = 3", len(got))
+		}
+	}
+
+	if !got[0].Equal(got[1]) {
+		t.Errorf("expected callbacks in the same frame to get the same time")
+	}
+	if !got[2].After(got[1]) {
+		t.Errorf("expected next frame to be later")
+	}
+}
+
+func TestUI_TimersStopOnQuit(t *testing.T) {
+	ui := NewTestUI(NewLabel(""), 1, 1)
+	stop := runTestUI(t, ui)
+
+	ui.Every(time.Millisecond, func() {})
+	ui.AfterFunc(time.Millisecond, func() {})
+
+	stop()
+
+	// The timers must not block trying to reach the stopped UI.
+	select {
+	case <-ui.done:
+	case <-time.After(time.Second):
+		t.Fatal("UI did not shut down")
+	}
+	time.Sleep(5 * time.Millisecond)
+}
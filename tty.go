@@ -0,0 +1,390 @@
+package tui
+
+import (
+	"errors"
+	"image"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+// defaultTTYSize is the size assumed for terminals that don't report one.
+var defaultTTYSize = image.Point{80, 24}
+
+// escapeTimeout is how long to wait for the rest of an escape sequence. The
+// bytes of a sequence arrive together, so a lone escape is the Esc key.
+const escapeTimeout = 50 * time.Millisecond
+
+// The escape sequences switching to the alternate screen and back, and
+// enabling and disabling mouse reporting in the SGR format.
+const (
+	enterAltScreen = "\x1b[?1049h"
+	exitAltScreen  = "\x1b[?1049l"
+	enableMouse    = "\x1b[?1000h\x1b[?1002h\x1b[?1006h"
+	disableMouse   = "\x1b[?1006l\x1b[?1002l\x1b[?1000l"
+)
+
+var errTTYClosed = errors.New("tui: terminal is not open")
+
+// ttyScreen is the screen of a UI that paints on an ANSISurface rather than a
+// tcell screen. It puts the terminal in raw mode, and turns its input into the
+// same events as tcell does, without needing a terminfo database.
+type ttyScreen struct {
+	// open opens a local terminal when the screen is initialized. The
+	// terminal is closed again when the screen is shut down. If open is
+	// nil, the screen reads from in and writes to out instead.
+	open func() (*os.File, error)
+
+	in  io.Reader
+	out io.Writer
+
+	// sizes receives the size of a terminal that isn't opened with open
+	// whenever it's resized.
+	sizes <-chan image.Point
+
+	// fullscreen switches to the alternate screen and enables the mouse.
+	// Otherwise, the UI is drawn inline and the mouse is left to the
+	// terminal, so that it can still be used to scroll and select text.
+	fullscreen bool
+
+	// surface is the surface painted on the terminal. It's detached when
+	// the screen is shut down, leaving the last frame on the terminal.
+	surface *ANSISurface
+
+	// tty is the terminal opened with open.
+	tty *os.File
+
+	// reader reads from in when it isn't a terminal opened with open. It
+	// stops once the screen is shut down, but a read can't be interrupted,
+	// so the reader started when the screen is initialized again waits for
+	// it to finish first.
+	reader *ttyReader
+
+	restore    func()
+	stopResize func()
+
+	mu    sync.Mutex
+	size  image.Point
+	input *ttyInput
+}
+
+// ttyInput is the input read from the terminal while the screen is active.
+type ttyInput struct {
+	reader  *ttyReader
+	resize  chan tcell.Event
+	quit    chan struct{}
+	decoder inputDecoder
+	pending []tcell.Event
+}
+
+// ttyReader reads from a terminal in the background.
+type ttyReader struct {
+	bytes chan []byte
+
+	// err is the error that stopped the reader. It's set before bytes is
+	// closed.
+	err error
+
+	// rest holds the bytes of a read that completed after the reader was
+	// stopped. It's set before done is closed.
+	rest []byte
+	done chan struct{}
+}
+
+// inputClosedEvent is sent once nothing more can be read from the terminal,
+// e.g. because the other end of a connection has gone away.
+type inputClosedEvent struct {
+	tcell.EventTime
+
+	err error
+}
+
+func (s *ttyScreen) Init() error {
+	in := &ttyInput{
+		resize: make(chan tcell.Event, 1),
+		quit:   make(chan struct{}),
+	}
+
+	if s.open != nil {
+		tty, err := s.open()
+		if err != nil {
+			return err
+		}
+		s.tty = tty
+		s.in, s.out = tty, tty
+		in.reader = newTTYReader(tty, in.quit, nil)
+	} else {
+		s.reader = newTTYReader(s.in, in.quit, s.reader)
+		in.reader = s.reader
+	}
+
+	s.restore = func() {}
+	if f, ok := s.in.(*os.File); ok {
+		restore, err := makeRaw(f)
+		if err != nil {
+			s.close()
+			return err
+		}
+		s.restore = restore
+	}
+
+	s.mu.Lock()
+	s.input = in
+	s.mu.Unlock()
+
+	resized := func(size image.Point) {
+		s.setSize(size)
+		select {
+		case in.resize <- tcell.NewEventResize(size.X, size.Y):
+		default:
+			// A resize is already waiting to be handled.
+		}
+	}
+
+	if s.tty != nil {
+		s.setSize(s.ttySize())
+		s.stopResize = notifyResize(func() {
+			resized(s.ttySize())
+		})
+	} else {
+		s.stopResize = watchSizes(s.sizes, resized)
+	}
+
+	if s.fullscreen {
+		io.WriteString(s, enterAltScreen)
+	}
+
+	return nil
+}
+
+// Fini leaves the last frame on the terminal, and restores it.
+func (s *ttyScreen) Fini() {
+	if s.surface != nil {
+		s.surface.Detach()
+	}
+	if s.fullscreen {
+		io.WriteString(s, disableMouse+exitAltScreen)
+	}
+
+	s.stopResize()
+	s.restore()
+
+	s.mu.Lock()
+	close(s.input.quit)
+	s.mu.Unlock()
+
+	s.close()
+}
+
+// close closes the terminal opened with open.
+func (s *ttyScreen) close() {
+	if s.tty == nil {
+		return
+	}
+	s.tty.Close()
+	s.tty = nil
+	s.in, s.out = nil, nil
+}
+
+// EnableMouse enables mouse reporting on full-screen terminals.
+func (s *ttyScreen) EnableMouse() {
+	if s.fullscreen {
+		io.WriteString(s, enableMouse)
+	}
+}
+
+// Sync redraws the whole frame, e.g. after the terminal was drawn over.
+func (s *ttyScreen) Sync() {
+	if s.surface != nil {
+		s.surface.Sync()
+	}
+}
+
+// PollEvent waits for the next event. It returns nil once the screen has been
+// shut down.
+func (s *ttyScreen) PollEvent() tcell.Event {
+	s.mu.Lock()
+	in := s.input
+	s.mu.Unlock()
+
+	for len(in.pending) == 0 {
+		var timeout <-chan time.Time
+		if in.decoder.waiting() {
+			timeout = time.After(escapeTimeout)
+		}
+
+		select {
+		case <-in.quit:
+			return nil
+		case ev := <-in.resize:
+			return ev
+		case b, ok := <-in.reader.bytes:
+			if !ok {
+				select {
+				case <-in.quit:
+					return nil
+				default:
+				}
+				ev := &inputClosedEvent{err: in.reader.err}
+				ev.SetEventNow()
+				return ev
+			}
+			in.pending = in.decoder.feed(b)
+		case <-timeout:
+			in.pending = in.decoder.flush()
+		}
+	}
+
+	ev := in.pending[0]
+	in.pending = in.pending[1:]
+	return ev
+}
+
+// Write writes directly to the terminal.
+func (s *ttyScreen) Write(b []byte) (int, error) {
+	if s.out == nil {
+		return 0, errTTYClosed
+	}
+	return s.out.Write(b)
+}
+
+// TPuts writes an escape sequence to the terminal.
+func (s *ttyScreen) TPuts(seq string) {
+	io.WriteString(s, seq)
+}
+
+// Size returns the size of the terminal.
+func (s *ttyScreen) Size() image.Point {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+func (s *ttyScreen) setSize(size image.Point) {
+	if size.X <= 0 || size.Y <= 0 {
+		size = defaultTTYSize
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.size = size
+}
+
+// ttySize returns the size of the terminal opened with open.
+func (s *ttyScreen) ttySize() image.Point {
+	size, err := terminalSize(s.tty)
+	if err != nil {
+		return defaultTTYSize
+	}
+	return size
+}
+
+// newTTYReader starts reading from r, until reading fails or quit is closed.
+// If prev is a reader that read from r before, it's waited for first, and
+// the bytes it read after it was stopped are delivered first.
+func newTTYReader(r io.Reader, quit <-chan struct{}, prev *ttyReader) *ttyReader {
+	tr := &ttyReader{
+		bytes: make(chan []byte),
+		done:  make(chan struct{}),
+	}
+
+	send := func(b []byte) bool {
+		select {
+		case tr.bytes <- b:
+			return true
+		case <-quit:
+			tr.rest = b
+			return false
+		}
+	}
+
+	go func() {
+		defer close(tr.done)
+		defer close(tr.bytes)
+
+		if prev != nil {
+			<-prev.done
+			if len(prev.rest) > 0 && !send(prev.rest) {
+				return
+			}
+			if prev.err != nil {
+				tr.err = prev.err
+				return
+			}
+		}
+
+		for {
+			select {
+			case <-quit:
+				return
+			default:
+			}
+
+			b := make([]byte, 256)
+			n, err := r.Read(b)
+			if n > 0 && !send(b[:n]) {
+				tr.err = err
+				return
+			}
+			if err != nil {
+				tr.err = err
+				return
+			}
+		}
+	}()
+
+	return tr
+}
+
+// watchSizes calls fn with each size received from c, until the returned
+// function is called.
+func watchSizes(c <-chan image.Point, fn func(image.Point)) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case size, ok := <-c:
+				if !ok {
+					c = nil
+					continue
+				}
+				fn(size)
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// ttySurface is the surface of a UI drawn on a ttyScreen. It covers the whole
+// width of the terminal, and either the whole height or the given number of
+// lines.
+type ttySurface struct {
+	*ANSISurface
+
+	screen *ttyScreen
+	height int
+}
+
+// Size returns the size of the surface, after resizing it to match the
+// terminal.
+func (s *ttySurface) Size() image.Point {
+	size := s.screen.Size()
+	if s.height > 0 {
+		size.Y = minOf(s.height, size.Y)
+	}
+	if size != s.ANSISurface.Size() {
+		s.SetSize(size.X, size.Y)
+	}
+	return size
+}
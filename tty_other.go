@@ -0,0 +1,28 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package tui
+
+import (
+	"errors"
+	"image"
+	"os"
+)
+
+var errTTYUnsupported = errors.New("tui: terminal mode is not supported on this platform")
+
+func openTTY() (*os.File, error) {
+	return nil, errTTYUnsupported
+}
+
+func makeRaw(f *os.File) (func(), error) {
+	return nil, errTTYUnsupported
+}
+
+func terminalSize(f *os.File) (image.Point, error) {
+	return image.Point{}, errTTYUnsupported
+}
+
+func notifyResize(fn func()) func() {
+	return func() {}
+}
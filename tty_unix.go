@@ -0,0 +1,86 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package tui
+
+import (
+	"image"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// openTTY opens the controlling terminal of the process.
+func openTTY() (*os.File, error) {
+	return os.OpenFile("/dev/tty", os.O_RDWR, 0)
+}
+
+// makeRaw puts the terminal in raw mode, and returns a function restoring
+// the previous mode. Files that aren't terminals are left as they are.
+func makeRaw(f *os.File) (func(), error) {
+	fd := int(f.Fd())
+
+	tio, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err == unix.ENOTTY {
+		return func() {}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	raw := *tio
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP |
+		unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Oflag &^= unix.OPOST
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		unix.IoctlSetTermios(fd, ioctlSetTermios, tio)
+	}, nil
+}
+
+// terminalSize returns the size of the terminal.
+func terminalSize(f *os.File) (image.Point, error) {
+	ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return image.Point{}, err
+	}
+	return image.Point{int(ws.Col), int(ws.Row)}, nil
+}
+
+// notifyResize calls fn whenever the terminal is resized, until the returned
+// function is called.
+func notifyResize(fn func()) func() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGWINCH)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-c:
+				fn()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(stop)
+		<-done
+	}
+}
@@ -0,0 +1,369 @@
+// Package tuitest provides helpers for testing tui-go widgets and
+// applications.
+package tuitest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+
+	tui "github.com/marcusolsson/tui-go"
+)
+
+// update is prefixed with the package name, so that it doesn't clash with the
+// -update flag that many test packages define for their own golden files.
+var update = flag.Bool("tuitest.update", false, "update the golden files of tuitest.AssertGolden in testdata")
+
+// maxCellDiffs is the number of mismatched cells listed by AssertGolden.
+const maxCellDiffs = 20
+
+// AssertGolden compares the characters and styles painted on a surface with
+// the golden file testdata/<name>.golden, and reports the cells that differ.
+// Run the tests with -tuitest.update to write the golden files from the
+// surfaces instead:
+//
+//	go test -run TestLogin -tuitest.update
+//
+// A golden file shows the characters painted on the surface, with unpainted
+// cells as dots, followed by the style of each cell as a letter, and the
+// styles the letters stand for:
+//
+//	size 8x2
+//	-- text --
+//	Login:..
+//	[OK]....
+//	-- styles --
+//	aaaaaa..
+//	bbbb....
+//	a: default
+//	b: fg=white bg=blue bold
+func AssertGolden(t testing.TB, s *tui.TestSurface, name string) {
+	t.Helper()
+
+	got := frameOf(s)
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(got.String()), 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v (run the tests with -tuitest.update to create it)", err)
+	}
+	want, err := parseFrame(string(b))
+	if err != nil {
+		t.Fatalf("%s:%v", path, err)
+	}
+
+	if diff := diffFrames(got, want); diff != "" {
+		t.Errorf("surface doesn't match %s (run the tests with -tuitest.update to update it):\n%s", path, diff)
+	}
+}
+
+// cell is a cell of a frame. The style is described as in a golden file, and
+// is empty for unpainted cells.
+type cell struct {
+	ch    rune
+	style string
+}
+
+// frame holds the cells of a surface.
+type frame struct {
+	width, height int
+	cells         []cell
+}
+
+func (f *frame) at(x, y int) cell {
+	return f.cells[y*f.width+x]
+}
+
+// frameOf returns the cells painted on a surface.
+func frameOf(s *tui.TestSurface) *frame {
+	size := s.Size()
+	f := &frame{
+		width:  size.X,
+		height: size.Y,
+		cells:  make([]cell, size.X*size.Y),
+	}
+	for y := 0; y < size.Y; y++ {
+		covered := 0
+		for x := 0; x < size.X; x++ {
+			ch, style, ok := s.Cell(x, y)
+			if !ok {
+				covered--
+				continue
+			}
+			// The characters of the cells covered by a wide rune
+			// aren't shown, so only their style is compared.
+			if covered > 0 {
+				ch = 0
+			}
+			f.cells[y*size.X+x] = cell{ch: ch, style: describeStyle(style)}
+			if covered--; ch != 0 {
+				covered = runewidth.RuneWidth(ch) - 1
+			}
+		}
+	}
+	return f
+}
+
+// styleKeys are the letters that stand for the styles in a golden file, in
+// the order the styles first appear.
+const styleKeys = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// styleKey returns the letter that stands for the i:th style. Once the letters
+// and digits run out, the letters of Latin Extended-A are used.
+func styleKey(i int) rune {
+	if i < len(styleKeys) {
+		return rune(styleKeys[i])
+	}
+	return rune(0x100 + i - len(styleKeys))
+}
+
+// String returns the frame in the golden file format.
+func (f *frame) String() string {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "size %dx%d\n", f.width, f.height)
+
+	buf.WriteString("-- text --\n")
+	for y := 0; y < f.height; y++ {
+		for x := 0; x < f.width; x++ {
+			c := f.at(x, y)
+			if c.style == "" {
+				buf.WriteRune('.')
+				continue
+			}
+			buf.WriteRune(c.ch)
+			// Wide runes cover the next cell.
+			if w := runewidth.RuneWidth(c.ch); w > 1 {
+				x += w - 1
+			}
+		}
+		buf.WriteRune('\n')
+	}
+
+	var (
+		keys   = make(map[string]rune)
+		styles []string
+	)
+	buf.WriteString("-- styles --\n")
+	for y := 0; y < f.height; y++ {
+		for x := 0; x < f.width; x++ {
+			c := f.at(x, y)
+			if c.style == "" {
+				buf.WriteRune('.')
+				continue
+			}
+			k, ok := keys[c.style]
+			if !ok {
+				k = styleKey(len(styles))
+				keys[c.style] = k
+				styles = append(styles, c.style)
+			}
+			buf.WriteRune(k)
+		}
+		buf.WriteRune('\n')
+	}
+	for _, s := range styles {
+		fmt.Fprintf(&buf, "%c: %s\n", keys[s], s)
+	}
+
+	return buf.String()
+}
+
+// parseFrame parses a golden file. Errors are prefixed with the line number.
+// maxCells limits the size of a frame, so that a corrupt size header can't
+// allocate more than a screen would ever need.
+const maxCells = 1 << 20
+
+// validSize parses a size header into w and h, and returns false unless it's
+// exactly as String writes it, with a size no larger than maxCells.
+func validSize(header string, w, h *int) bool {
+	if _, err := fmt.Sscanf(header, "size %dx%d", w, h); err != nil {
+		return false
+	}
+	if header != fmt.Sprintf("size %dx%d", *w, *h) {
+		return false
+	}
+	return *w >= 0 && *h >= 0 && (*h == 0 || *w <= maxCells / *h)
+}
+
+func parseFrame(s string) (*frame, error) {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+
+	f := &frame{}
+	if !validSize(lines[0], &f.width, &f.height) {
+		return nil, fmt.Errorf("1: invalid size: %q", lines[0])
+	}
+	if len(lines) < 2*f.height+3 {
+		return nil, fmt.Errorf("%d: unexpected end of file", len(lines))
+	}
+	f.cells = make([]cell, f.width*f.height)
+	text := lines[2 : 2+f.height]
+	keys := lines[3+f.height : 3+2*f.height]
+
+	for i, want := range []string{"-- text --", "-- styles --"} {
+		if n := 1 + i*(f.height+1); lines[n] != want {
+			return nil, fmt.Errorf("%d: got %q; want %q", n+1, lines[n], want)
+		}
+	}
+
+	styles := make(map[rune]string)
+	for i, l := range lines[3+2*f.height:] {
+		n := 4 + 2*f.height + i
+		parts := strings.SplitN(l, ": ", 2)
+		if len(parts) != 2 || len([]rune(parts[0])) != 1 {
+			return nil, fmt.Errorf("%d: invalid style: %q", n, l)
+		}
+		styles[[]rune(parts[0])[0]] = parts[1]
+	}
+
+	for y := 0; y < f.height; y++ {
+		x := 0
+		for _, r := range text[y] {
+			if x >= f.width {
+				return nil, fmt.Errorf("%d: line is longer than %d cells", 3+y, f.width)
+			}
+			f.cells[y*f.width+x].ch = r
+			if w := runewidth.RuneWidth(r); w > 1 {
+				x += w
+			} else {
+				x++
+			}
+		}
+		// Editors may strip the spaces at the end of a line.
+		for ; x < f.width; x++ {
+			f.cells[y*f.width+x].ch = ' '
+		}
+
+		x = 0
+		for _, k := range keys[y] {
+			if x >= f.width {
+				return nil, fmt.Errorf("%d: line is longer than %d cells", 4+f.height+y, f.width)
+			}
+			if k == '.' {
+				f.cells[y*f.width+x] = cell{}
+			} else if style, ok := styles[k]; ok {
+				f.cells[y*f.width+x].style = style
+			} else {
+				return nil, fmt.Errorf("%d: unknown style %q", 4+f.height+y, k)
+			}
+			x++
+		}
+	}
+
+	return f, nil
+}
+
+// diffFrames returns the frames side by side along with the cells that
+// differ, or an empty string if they're the same.
+func diffFrames(got, want *frame) string {
+	if got.String() == want.String() {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "got:\n%s\nwant:\n%s\n", got, want)
+
+	if got.width != want.width || got.height != want.height {
+		fmt.Fprintf(&buf, "got size %dx%d; want %dx%d\n", got.width, got.height, want.width, want.height)
+		return buf.String()
+	}
+
+	n := 0
+	for y := 0; y < got.height; y++ {
+		for x := 0; x < got.width; x++ {
+			g, w := got.at(x, y), want.at(x, y)
+			if g == w {
+				continue
+			}
+			if n++; n > maxCellDiffs {
+				buf.WriteString("...\n")
+				return buf.String()
+			}
+			fmt.Fprintf(&buf, "cell %d,%d: got %s; want %s\n", x, y, g, w)
+		}
+	}
+	return buf.String()
+}
+
+// String describes a cell in a diff.
+func (c cell) String() string {
+	switch {
+	case c.style == "":
+		return "unpainted"
+	case c.ch == 0:
+		return fmt.Sprintf("covered by a wide rune (%s)", c.style)
+	}
+	return fmt.Sprintf("%s (%s)", strconv.QuoteRune(c.ch), c.style)
+}
+
+var colorNames = map[tui.Color]string{
+	tui.ColorBlack:   "black",
+	tui.ColorWhite:   "white",
+	tui.ColorRed:     "red",
+	tui.ColorGreen:   "green",
+	tui.ColorBlue:    "blue",
+	tui.ColorCyan:    "cyan",
+	tui.ColorMagenta: "magenta",
+	tui.ColorYellow:  "yellow",
+}
+
+// describeStyle describes a style, e.g. "fg=red bold". Colors other than the
+// named ones are written as numbers, and decorations that are explicitly off
+// are written as e.g. "bold=off".
+func describeStyle(s tui.Style) string {
+	var parts []string
+	for _, c := range []struct {
+		name  string
+		color tui.Color
+	}{
+		{"fg", s.Fg},
+		{"bg", s.Bg},
+	} {
+		if c.color == tui.ColorDefault {
+			continue
+		}
+		name, ok := colorNames[c.color]
+		if !ok {
+			name = strconv.Itoa(int(c.color))
+		}
+		parts = append(parts, c.name+"="+name)
+	}
+	for _, d := range []struct {
+		name string
+		dec  tui.Decoration
+	}{
+		{"bold", s.Bold},
+		{"dim", s.Dim},
+		{"underline", s.Underline},
+		{"reverse", s.Reverse},
+	} {
+		switch d.dec {
+		case tui.DecorationOn:
+			parts = append(parts, d.name)
+		case tui.DecorationOff:
+			parts = append(parts, d.name+"=off")
+		}
+	}
+	if len(parts) == 0 {
+		return "default"
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,138 @@
+package tuitest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	tui "github.com/marcusolsson/tui-go"
+)
+
+// paintDialog paints a small dialog with a few styles, wide runes and a color
+// beyond the named ones.
+func paintDialog(title string) *tui.TestSurface {
+	theme := tui.NewTheme()
+	theme.SetStyle("label.title", tui.Style{Fg: tui.ColorWhite, Bg: tui.ColorBlue, Bold: tui.DecorationOn})
+	theme.SetStyle("button.focused", tui.Style{Fg: tui.Color(196), Reverse: tui.DecorationOn})
+
+	label := tui.NewLabel(title)
+	label.SetStyleName("title")
+
+	btn := tui.NewButton("[世界]")
+	btn.SetFocused(true)
+
+	surface := tui.NewTestSurface(10, 3)
+	tui.NewPainter(surface, theme).Repaint(tui.NewVBox(label, btn, tui.NewSpacer()))
+	return surface
+}
+
+func TestAssertGolden(t *testing.T) {
+	AssertGolden(t, paintDialog("Hello"), "dialog")
+}
+
+// recorder records the failures reported by AssertGolden.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+// assertGolden calls AssertGolden, and returns the failures it reported.
+func assertGolden(t *testing.T, s *tui.TestSurface, name string) []string {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		AssertGolden(r, s, name)
+	}()
+	<-done
+	return r.errors
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+func TestAssertGolden_Diff(t *testing.T) {
+	if *update {
+		t.Skip("not comparing while updating")
+	}
+
+	errs := assertGolden(t, paintDialog("Hallo!"), "dialog")
+	if len(errs) != 1 {
+		t.Fatalf("got %d errors; want 1", len(errs))
+	}
+	for _, want := range []string{
+		`cell 1,0: got 'a' (fg=white bg=blue bold); want 'e' (fg=white bg=blue bold)`,
+		`cell 5,0: got '!' (fg=white bg=blue bold); want ' ' (default)`,
+	} {
+		if !strings.Contains(errs[0], want) {
+			t.Errorf("got = %s\n\nwant it to contain %q", errs[0], want)
+		}
+	}
+	if n := strings.Count(errs[0], "\ncell "); n != 2 {
+		t.Errorf("got %d mismatched cells; want 2", n)
+	}
+
+	errs = assertGolden(t, paintDialog("Hello"), "missing")
+	if len(errs) != 1 || !strings.Contains(errs[0], "-tuitest.update") {
+		t.Errorf("got = %q; want a hint to run with -tuitest.update", errs)
+	}
+}
+
+func TestParseFrame(t *testing.T) {
+	want := frameOf(paintDialog("Hello"))
+
+	got, err := parseFrame(want.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := diffFrames(got, want); diff != "" {
+		t.Error(diff)
+	}
+
+	for _, tt := range []struct {
+		in      string
+		wantErr string
+	}{
+		{"size 2\n", `1: invalid size: "size 2"`},
+		{"size -2x1\n-- text --\nab\n-- styles --\naa\n", `1: invalid size: "size -2x1"`},
+		{"size 2x-1\n-- text --\n-- styles --\n", `1: invalid size: "size 2x-1"`},
+		{"size 2x1x\n-- text --\nab\n-- styles --\naa\n", `1: invalid size: "size 2x1x"`},
+		{"size 2x01\n-- text --\nab\n-- styles --\naa\n", `1: invalid size: "size 2x01"`},
+		{"size 4000000x1000000\n", `1: invalid size: "size 4000000x1000000"`},
+		{"size 2x1000\n-- text --\n", "2: unexpected end of file"},
+		{"size 2x1\n-- text --\nab\n", "3: unexpected end of file"},
+		{"size 2x1\n-- text --\nab\n-- style --\naa\n", `4: got "-- style --"; want "-- styles --"`},
+		{"size 2x1\n-- text --\nabc\n-- styles --\naa\na: default\n", "3: line is longer than 2 cells"},
+		{"size 2x1\n-- text --\nab\n-- styles --\nab\na: default\n", `5: unknown style 'b'`},
+		{"size 2x1\n-- text --\nab\n-- styles --\naa\na default\n", `6: invalid style: "a default"`},
+	} {
+		if _, err := parseFrame(tt.in); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%q: got = %v; want = %s", tt.in, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDescribeStyle(t *testing.T) {
+	for _, tt := range []struct {
+		style tui.Style
+		want  string
+	}{
+		{tui.Style{}, "default"},
+		{tui.Style{Fg: tui.ColorRed, Bg: tui.Color(100)}, "fg=red bg=100"},
+		{tui.Style{Bold: tui.DecorationOn, Dim: tui.DecorationOn, Underline: tui.DecorationOn, Reverse: tui.DecorationOn}, "bold dim underline reverse"},
+		{tui.Style{Bg: tui.ColorBlack, Underline: tui.DecorationOff}, "bg=black underline=off"},
+	} {
+		if got := describeStyle(tt.style); got != tt.want {
+			t.Errorf("%+v: got = %q; want = %q", tt.style, got, tt.want)
+		}
+	}
+}
@@ -1,5 +1,13 @@
 package tui
 
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"time"
+)
+
 // UI defines the operations needed by the underlying engine.
 type UI interface {
 	// SetWidget sets the root widget of the UI.
@@ -10,13 +18,76 @@ type UI interface {
 	SetKeybinding(seq string, fn func())
 	// ClearKeybindings removes all previous set keybindings.
 	ClearKeybindings()
+	// CheckKeybindings returns an error if any keybinding is invalid or
+	// conflicts with another.
+	CheckKeybindings() error
+	// ActiveKeymaps returns the keymaps that currently apply, starting with
+	// the one of the focused widget and ending with the global keybindings.
+	ActiveKeymaps() []*Keymap
+	// SetChordTimeout sets how long to wait for the next key of a key
+	// sequence, e.g. "Ctrl+X Ctrl+S", before cancelling it.
+	SetChordTimeout(d time.Duration)
+	// PendingChord returns the keys typed so far of a partially typed key
+	// sequence.
+	PendingChord() string
+	// OnPendingChord sets a function to be called whenever the pending
+	// chord changes.
+	OnPendingChord(fn func(chord string))
 	// SetFocusChain sets a chain of widgets that determines focus order.
 	SetFocusChain(ch FocusChain)
+	// PushLayer draws a widget on top of the others, e.g. a dialog, and
+	// traps the input and focus inside it.
+	PushLayer(w Widget, opts LayerOptions)
+	// PopLayer removes the topmost layer, and restores the focus to where
+	// it was before the layer was pushed.
+	PopLayer()
+	// RemoveLayer removes the layer showing the given widget, even if other
+	// layers have been pushed on top of it since.
+	RemoveLayer(w Widget)
+	// SetDirectionalFocus sets whether the arrow keys, or Alt and the arrow
+	// keys, move the focus to the nearest widget in that direction.
+	SetDirectionalFocus(enabled bool)
+	// OnFocusChanged sets a function to be called whenever the focus moves
+	// from one widget to another, e.g. with Tab or a mouse click.
+	OnFocusChanged(fn func(from, to Widget))
 	// Run starts the UI goroutine and blocks either Quit was called or an error occurred.
 	Run() error
+	// RunContext is like Run, but also returns when the context is
+	// cancelled.
+	RunContext(ctx context.Context) error
+	// OnQuit registers a function to be called when the UI shuts down,
+	// after the terminal has been restored.
+	OnQuit(fn func())
 	// Update schedules work in the UI thread and await its completion.
-	// Note that calling Update from the UI thread will result in deadlock.
+	// When called from the UI thread, fn is run immediately.
 	Update(fn func())
+	// Post schedules work in the UI thread without waiting for it to
+	// complete. It never blocks, and is safe to call from any goroutine.
+	Post(fn func())
+	// AfterFunc waits for the duration to elapse and then calls fn in the UI
+	// goroutine.
+	AfterFunc(d time.Duration, fn func()) *Timer
+	// Every calls fn in the UI goroutine each time the duration elapses, until
+	// the returned Timer is stopped.
+	Every(d time.Duration, fn func()) *Timer
+	// RequestFrame schedules fn to be called in the UI goroutine before the
+	// next frame is drawn. Call it again from fn to keep animating.
+	RequestFrame(fn func(t time.Time))
+	// OnResize sets a function to be called with the new size of the screen
+	// whenever the terminal is resized.
+	OnResize(fn func(size image.Point))
+	// Size returns the size of the screen, in cells.
+	Size() image.Point
+	// SetMaxFrameRate limits how many times per second the UI is repainted.
+	// Events that arrive between two frames are handled together, followed
+	// by a single repaint. A rate of zero or less disables the limit.
+	SetMaxFrameRate(fps int)
+	// Suspend restores the terminal while fn runs, e.g. to open an editor,
+	// and then resumes the UI with a full repaint.
+	Suspend(fn func() error) error
+	// Record records every frame painted from now on to w, as an asciicast
+	// v2 recording. Record(nil) stops recording.
+	Record(w io.Writer)
 	// Quit shuts down the UI goroutine.
 	Quit()
 	// Repaint the UI
@@ -27,3 +98,53 @@ type UI interface {
 func New(root Widget) (UI, error) {
 	return newTcellUI(root)
 }
+
+// NewInline returns a new UI that draws in the given number of lines starting
+// at the cursor, rather than taking over the whole terminal. The UI uses the
+// full width of the terminal, and follows it when the terminal is resized.
+// When the UI shuts down, the last frame is left in the scrollback, and the
+// cursor is moved to the line below it.
+//
+// An inline UI leaves the mouse to the terminal, so that it can still be used
+// to scroll and select text.
+func NewInline(root Widget, height int) (UI, error) {
+	if height < 1 {
+		return nil, fmt.Errorf("tui: invalid height %d", height)
+	}
+	return newInlineUI(root, height, openTTY), nil
+}
+
+// NewStreamUI returns a new UI that reads the input of a terminal from in, and
+// draws on it by writing to out, rather than using the controlling terminal of
+// the process. Use it to serve the same application to several users at once,
+// e.g. over SSH, with a UI for each session.
+//
+// If in is a terminal, e.g. one end of a pseudo-terminal, it's put in raw mode
+// while the UI runs. Run returns once nothing more can be read from in, e.g.
+// because the connection was closed. Unlike the UI returned by New, it
+// doesn't stop the process when Ctrl+Z is pressed, and leaves the signals
+// sent to the process, e.g. SIGTERM, to the rest of the application.
+//
+// The UI stops reading from in once it has shut down. A read that is still in
+// progress then can't be interrupted, so the bytes it returns are dropped.
+func NewStreamUI(root Widget, in io.Reader, out io.Writer, opts StreamOptions) (UI, error) {
+	ui, err := newStreamUI(root, in, out, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ui, nil
+}
+
+// PanicError is returned by Run when a panic occurs in the UI goroutine, e.g.
+// in a widget or an event handler. The terminal is restored before Run
+// returns.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n\n%s", e.Value, e.Stack)
+}
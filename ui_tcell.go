@@ -1,26 +1,126 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"image"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/gdamore/tcell"
 )
 
 var _ UI = &tcellUI{}
 
+var errAlreadyRun = errors.New("tui: UI has already run")
+
+// terminal is the part of tcell.Screen used to set up the terminal and to read
+// events from it. The UI paints on a Surface.
+type terminal interface {
+	Init() error
+	Fini()
+	EnableMouse()
+	PollEvent() tcell.Event
+	Sync()
+}
+
 type tcellUI struct {
 	painter *Painter
 	root    Widget
 
-	keybindings []*keybinding
+	// keymap holds the global keybindings.
+	keymap *Keymap
 
-	quit chan struct{}
+	// pendingKeys holds the keys of a partially typed key sequence, from
+	// the bindings in pendingKeymap. The sequence is cancelled when
+	// chordTimer fires.
+	pendingKeys    []KeyEvent
+	pendingKeymap  *Keymap
+	chordTimer     *Timer
+	chordTimeout   time.Duration
+	onPendingChord func(chord string)
 
-	screen tcell.Screen
+	quit      chan struct{}
+	quitHooks []func()
+
+	// err is returned by Run once the UI has shut down.
+	err error
+
+	// done is closed when Run returns.
+	done chan struct{}
+
+	// running is set by the first call to Run. A UI can only run once.
+	running int32
+
+	screen terminal
+
+	// newScreen returns the screen to use after the UI has been suspended.
+	newScreen    func() (terminal, error)
+	screenActive bool
+
+	// pollStop and pollDone stop the goroutine polling the screen for
+	// events, and signal that it has exited.
+	pollStop chan struct{}
+	pollDone chan struct{}
+
+	// handleSignals ends the UI when the process receives SIGINT, SIGTERM
+	// or SIGHUP. It's only set for UIs on the controlling terminal, since
+	// the signals concern the whole process.
+	handleSignals bool
+
+	// jobControl enables stopping the process with Ctrl+Z. jobSignals
+	// receives the job control signals.
+	jobControl bool
+	jobSignals chan os.Signal
 
 	kbFocus *kbFocusController
+	mouse   *mouseController
+
+	// layers holds the root widget and the layers drawn on top of it.
+	layers *layerStack
 
 	eventQueue chan event
+
+	// posted holds the functions queued by Post. postSignal is notified
+	// whenever a function is added.
+	postMu     sync.Mutex
+	posted     []func()
+	postSignal chan struct{}
+
+	// looping is set while the event loop runs. Until it is, no caller can
+	// be on the loop goroutine, and onLoop doesn't need to look.
+	looping int32
+
+	frameMu        sync.Mutex
+	frameCallbacks []func(time.Time)
+
+	// frameInterval is the minimum time between two repaints.
+	frameInterval time.Duration
+	lastPaint     time.Time
+
+	// needsPaint is set when an event may have changed the widgets, and
+	// cleared when the next frame is painted.
+	needsPaint bool
+
+	// resizePending is set while a resize is waiting to be handled, so that
+	// a burst of resize events results in a single layout pass.
+	resizePending int32
+
+	// size is the size of the screen as of the last resize.
+	size     image.Point
+	onResize func(size image.Point)
+
+	// recorder records the frames painted, if set. It wraps the surface of
+	// the painter.
+	recorder *Recorder
 }
 
 func newTcellUI(root Widget) (*tcellUI, error) {
@@ -32,25 +132,62 @@ func newTcellUI(root Widget) (*tcellUI, error) {
 	s := &tcellSurface{
 		screen: screen,
 	}
+
+	ui := newScreenUI(root, screen, s)
+	ui.handleSignals = true
+	ui.jobControl = len(jobControlSignals) > 0
+	ui.newScreen = func() (terminal, error) {
+		screen, err := tcell.NewScreen()
+		if err != nil {
+			return nil, err
+		}
+		s.screen = screen
+		return screen, nil
+	}
+
+	return ui, nil
+}
+
+// newScreenUI returns a UI that receives events from the given screen and
+// paints on the given surface.
+func newScreenUI(root Widget, screen terminal, s Surface) *tcellUI {
 	p := NewPainter(s, DefaultTheme)
 
 	return &tcellUI{
-		painter:     p,
-		root:        root,
-		keybindings: make([]*keybinding, 0),
-		quit:        make(chan struct{}, 1),
-		screen:      screen,
-		kbFocus:     &kbFocusController{chain: DefaultFocusChain},
-		eventQueue:  make(chan event),
-	}, nil
+		painter:    p,
+		root:       root,
+		keymap:     NewKeymap("global"),
+		quit:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		screen:     screen,
+		kbFocus:    &kbFocusController{chain: DefaultFocusChain},
+		mouse:      &mouseController{},
+		layers:     &layerStack{root: root},
+		eventQueue: make(chan event),
+		postSignal: make(chan struct{}, 1),
+
+		frameInterval: time.Second / DefaultMaxFrameRate,
+		chordTimeout:  DefaultChordTimeout,
+	}
 }
 
 func (ui *tcellUI) Repaint() {
-	ui.painter.Repaint(ui.root)
+	ui.paint()
+}
+
+// SetMaxFrameRate limits how many times per second the UI is repainted. A
+// rate of zero or less repaints after every event.
+func (ui *tcellUI) SetMaxFrameRate(fps int) {
+	if fps <= 0 {
+		ui.frameInterval = 0
+		return
+	}
+	ui.frameInterval = time.Second / time.Duration(fps)
 }
 
 func (ui *tcellUI) SetWidget(w Widget) {
 	ui.root = w
+	ui.layers.root = w
 }
 
 func (ui *tcellUI) SetTheme(t *Theme) {
@@ -58,121 +195,887 @@ func (ui *tcellUI) SetTheme(t *Theme) {
 }
 
 func (ui *tcellUI) SetFocusChain(chain FocusChain) {
-	if ui.kbFocus.focusedWidget != nil {
-		ui.kbFocus.focusedWidget.SetFocused(false)
+	// The focus is trapped in the layers until they are removed.
+	if len(ui.layers.layers) > 0 {
+		ui.layers.layers[0].prevChain = chain
+		ui.layers.layers[0].prevFocus = chain.FocusDefault()
+		return
+	}
+
+	ui.kbFocus.chain = chain
+	ui.kbFocus.setFocus(chain.FocusDefault())
+}
+
+// SetDirectionalFocus sets whether the arrow keys move the focus to the
+// nearest widget of the focus chain in that direction. Plain arrow keys only
+// move the focus if the focused widget doesn't use them, while Alt+arrow
+// moves the focus from any widget.
+func (ui *tcellUI) SetDirectionalFocus(enabled bool) {
+	ui.kbFocus.directional = enabled
+}
+
+// PushLayer draws a widget on top of the others, e.g. a dialog. While the
+// layer is on top, the widgets below it receive neither key nor mouse events,
+// and the focus moves between the widgets of the layer only. The global
+// keybindings still apply.
+func (ui *tcellUI) PushLayer(w Widget, opts LayerOptions) {
+	chain := opts.FocusChain
+	if chain == nil {
+		chain = NewTreeFocusChain(w)
+	}
+
+	l := &layer{
+		widget:    w,
+		opts:      opts,
+		prevChain: ui.kbFocus.chain,
+		prevFocus: ui.kbFocus.focusedWidget,
 	}
+	ui.layers.layers = append(ui.layers.layers, l)
+
+	// Lay out the layer right away, so that it's ready for events that
+	// arrive before the next frame.
+	l.bounds = ui.layers.place(l, ui.layers.Size())
+	w.Resize(l.bounds.Size())
 
 	ui.kbFocus.chain = chain
-	ui.kbFocus.focusedWidget = chain.FocusDefault()
+	ui.kbFocus.setFocus(chain.FocusDefault())
+}
+
+// PopLayer removes the topmost layer, and gives the focus back to the widget
+// that had it when the layer was pushed.
+func (ui *tcellUI) PopLayer() {
+	if l := ui.layers.top(); l != nil {
+		ui.RemoveLayer(l.widget)
+	}
+}
+
+// RemoveLayer removes the layer showing the given widget. If it's the topmost
+// layer, the focus goes back to the widget that had it when the layer was
+// pushed. Otherwise, the focus is left alone, and goes back to where it was
+// below the removed layer once the layers above it are removed. OnRemove is
+// called last, so that it may remove other layers.
+func (ui *tcellUI) RemoveLayer(w Widget) {
+	layers := ui.layers.layers
+	for i := len(layers) - 1; i >= 0; i-- {
+		l := layers[i]
+		if l.widget != w {
+			continue
+		}
+		ui.layers.layers = append(layers[:i:i], layers[i+1:]...)
+
+		if i < len(layers)-1 {
+			above := layers[i+1]
+			above.prevChain, above.prevFocus = l.prevChain, l.prevFocus
+		} else {
+			ui.kbFocus.chain = l.prevChain
+			ui.kbFocus.setFocus(l.prevFocus)
+		}
 
-	if ui.kbFocus.focusedWidget != nil {
-		ui.kbFocus.focusedWidget.SetFocused(true)
+		if l.opts.OnRemove != nil {
+			l.opts.OnRemove()
+		}
+		return
 	}
 }
 
+// inputRoot returns the root of the widgets that receive key events.
+func (ui *tcellUI) inputRoot() Widget {
+	w, _ := ui.layers.inputRoot()
+	return w
+}
+
+// OnFocusChanged sets a function to be called whenever the focus moves from
+// one widget to another. Either widget may be nil.
+func (ui *tcellUI) OnFocusChanged(fn func(from, to Widget)) {
+	ui.kbFocus.onChange = fn
+}
+
+// SetKeybinding sets a global callback for when a key sequence is pressed.
+// The sequence is either a single key, e.g. "Ctrl+S", or several keys
+// separated by spaces, e.g. "Ctrl+X Ctrl+S" or "g g". Invalid and conflicting
+// keybindings are logged, and can be checked with CheckKeybindings.
 func (ui *tcellUI) SetKeybinding(seq string, fn func()) {
-	ui.keybindings = append(ui.keybindings, &keybinding{
-		sequence: seq,
-		handler:  fn,
-	})
+	ui.keymap.SetKeybinding(seq, fn)
 }
 
-// ClearKeybindings reinitialises ui.keybindings so as to revert to a
-// clear/original state
+// ClearKeybindings removes all global keybindings. The keymaps of the
+// widgets are left as they are.
 func (ui *tcellUI) ClearKeybindings() {
-	ui.keybindings = make([]*keybinding, 0)
+	ui.keymap.Clear()
+	ui.setPendingKeys(nil, nil)
+}
+
+// CheckKeybindings checks the global keybindings and the keymaps of the
+// widgets that currently have focus. It returns an error describing the first
+// invalid key sequence, or the first pair of conflicting keybindings in the
+// same keymap.
+func (ui *tcellUI) CheckKeybindings() error {
+	for _, m := range ui.ActiveKeymaps() {
+		if err := m.Check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ActiveKeymaps returns the keymaps that currently apply, in the order they
+// get to handle keys: the keymaps of the focused widget and the containers
+// it's in, followed by the global keybindings.
+func (ui *tcellUI) ActiveKeymaps() []*Keymap {
+	var ms []*Keymap
+	for _, w := range ui.kbFocus.path(ui.inputRoot()) {
+		if m := widgetKeymap(w); m != nil {
+			ms = append(ms, m)
+		}
+	}
+	return append(ms, ui.keymap)
+}
+
+// SetChordTimeout sets how long to wait for the next key of a partially
+// typed key sequence before cancelling it. A timeout of zero or less waits
+// forever.
+func (ui *tcellUI) SetChordTimeout(d time.Duration) {
+	ui.chordTimeout = d
+}
+
+// PendingChord returns the keys typed so far of a partially typed key
+// sequence, e.g. "Ctrl+X", or an empty string if there is none.
+func (ui *tcellUI) PendingChord() string {
+	return keySequenceName(ui.pendingKeys)
+}
+
+// OnPendingChord sets a function to be called whenever the pending chord
+// changes, e.g. to show it in a status bar. The chord is empty once the key
+// sequence has been completed, cancelled or has timed out.
+func (ui *tcellUI) OnPendingChord(fn func(chord string)) {
+	ui.onPendingChord = fn
+}
+
+func (ui *tcellUI) setPendingKeys(m *Keymap, evs []KeyEvent) {
+	if ui.chordTimer != nil {
+		ui.chordTimer.Stop()
+		ui.chordTimer = nil
+	}
+
+	if len(evs) == 0 && len(ui.pendingKeys) == 0 {
+		return
+	}
+	ui.pendingKeys = evs
+	ui.pendingKeymap = m
+
+	if len(evs) > 0 && ui.chordTimeout > 0 {
+		ui.chordTimer = ui.AfterFunc(ui.chordTimeout, ui.chordTimedOut)
+	}
+
+	if ui.onPendingChord != nil {
+		ui.onPendingChord(ui.PendingChord())
+	}
+}
+
+// handleKeybindings runs the keybindings in the keymap matching the key
+// event, appended to the pending key sequence if there is one. It returns
+// false if no keybinding matches, in which case the pending sequence is
+// cancelled.
+func (ui *tcellUI) handleKeybindings(m *Keymap, ev KeyEvent) bool {
+	seq := append(ui.pendingKeys[:len(ui.pendingKeys):len(ui.pendingKeys)], ev)
+
+	full, partial := matchKeybindings(m.bindings, seq)
+
+	if partial {
+		// Wait for more keys, even if a shorter sequence matches. It runs
+		// if the sequence times out.
+		ui.setPendingKeys(m, seq)
+		return true
+	}
+
+	ui.setPendingKeys(nil, nil)
+
+	for _, b := range full {
+		b.handler()
+	}
+
+	return len(full) > 0
+}
+
+// chordTimedOut cancels the pending key sequence, and runs the keybindings
+// that match the keys typed so far.
+func (ui *tcellUI) chordTimedOut() {
+	full, _ := matchKeybindings(ui.pendingKeymap.bindings, ui.pendingKeys)
+
+	ui.chordTimer = nil
+	ui.setPendingKeys(nil, nil)
+
+	for _, b := range full {
+		b.handler()
+	}
 }
 
 func (ui *tcellUI) Run() error {
-	if err := ui.screen.Init(); err != nil {
-		return err
+	return ui.RunContext(context.Background())
+}
+
+// RunContext runs the UI until Quit is called, or the context is cancelled.
+// A UI on the controlling terminal, as returned by New or NewInline, also
+// stops when the process receives SIGINT, SIGTERM or SIGHUP. If the context
+// is cancelled, the context's error is returned.
+//
+// A panic in the UI goroutine is recovered and returned as a *PanicError. In
+// every case, the terminal is restored and the OnQuit hooks are called before
+// RunContext returns. A UI can only run once; calling RunContext again returns
+// an error.
+func (ui *tcellUI) RunContext(ctx context.Context) (err error) {
+	if !atomic.CompareAndSwapInt32(&ui.running, 0, 1) {
+		return errAlreadyRun
 	}
+	defer close(ui.done)
+
+	atomic.StoreInt32(&ui.looping, 1)
+	defer atomic.StoreInt32(&ui.looping, 0)
+
+	runOnLoop(func() {
+		err = ui.run(ctx)
+	})
+	return err
+}
+
+// run runs the event loop until the UI shuts down. See RunContext.
+func (ui *tcellUI) run(ctx context.Context) (err error) {
+	defer ui.runQuitHooks()
 
-	failed := true
+	// Deferred before Fini, so that the terminal is restored by the time
+	// the panic is returned.
 	defer func() {
-		if failed {
-			ui.screen.Fini()
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
 		}
 	}()
 
+	// sig stays nil for UIs that don't handle signals, so that they're
+	// left to the rest of the process.
+	var sig chan os.Signal
+	if ui.handleSignals {
+		sig = make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		defer signal.Stop(sig)
+	}
+
+	ui.jobSignals = make(chan os.Signal, 1)
+	if ui.jobControl {
+		signal.Notify(ui.jobSignals, jobControlSignals...)
+		defer signal.Stop(ui.jobSignals)
+	}
+
+	if err := ui.initScreen(); err != nil {
+		return err
+	}
+
+	defer ui.finiScreen()
+
 	if w := ui.kbFocus.chain.FocusDefault(); w != nil {
-		w.SetFocused(true)
-		ui.kbFocus.focusedWidget = w
+		ui.kbFocus.setFocus(w)
+	}
+
+	ui.size = ui.painter.surface.Size()
+
+	// Run anything posted before the UI started, then lay out and draw the
+	// widgets before any events arrive.
+	ui.runPosted()
+	ui.paint()
+
+	ui.startPolling()
+
+	// frame fires when the next frame may be painted. It's only set while a
+	// repaint is being held back by the frame rate limit.
+	var frame <-chan time.Time
+
+	for {
+		select {
+		case <-ui.quit:
+			ui.flush()
+			return ui.err
+		case <-ctx.Done():
+			ui.flush()
+			return ctx.Err()
+		case s := <-sig:
+			logger.Printf("Received signal: %v", s)
+			ui.flush()
+			return nil
+		case s := <-ui.jobSignals:
+			if s == suspendSignal {
+				ui.suspendProcess()
+			} else {
+				// The process was stopped and resumed by someone else,
+				// and the terminal may have been drawn over meanwhile.
+				ui.screen.Sync()
+			}
+		case ev := <-ui.eventQueue:
+			ui.handleEvent(ev)
+		case <-ui.postSignal:
+			ui.runPosted()
+			ui.needsPaint = true
+		case <-frame:
+			frame = nil
+		}
+
+		if ui.kbFocus.dropDisabled(ui.inputRoot()) {
+			ui.needsPaint = true
+		}
+
+		if !ui.needsPaint || frame != nil {
+			continue
+		}
+
+		if wait := time.Until(ui.lastPaint.Add(ui.frameInterval)); wait > 0 {
+			frame = time.After(wait)
+			continue
+		}
+
+		ui.paint()
+	}
+}
+
+func (ui *tcellUI) initScreen() error {
+	if err := ui.screen.Init(); err != nil {
+		return err
+	}
+	ui.screenActive = true
+
+	if s, ok := ui.screen.(tcell.Screen); ok {
+		s.SetStyle(tcell.StyleDefault)
+		s.Clear()
+	}
+	ui.screen.EnableMouse()
+
+	setBracketedPaste(ui.screen, true)
+
+	return nil
+}
+
+func (ui *tcellUI) finiScreen() {
+	if ui.screenActive {
+		setBracketedPaste(ui.screen, false)
+		ui.screen.Fini()
+		ui.screenActive = false
 	}
+}
+
+// startPolling starts a goroutine that forwards events from the screen to
+// the event loop.
+func (ui *tcellUI) startPolling() {
+	ui.pollStop = make(chan struct{})
+	ui.pollDone = make(chan struct{})
+
+	go ui.poll(ui.screen, ui.pollStop, ui.pollDone)
+}
+
+// stopPolling waits for the polling goroutine to exit. The screen must have
+// been shut down first. Events that haven't been handled yet are dropped.
+func (ui *tcellUI) stopPolling() {
+	close(ui.pollStop)
+	<-ui.pollDone
+}
 
-	ui.screen.SetStyle(tcell.StyleDefault)
-	ui.screen.Clear()
+func (ui *tcellUI) poll(screen terminal, stop, done chan struct{}) {
+	defer close(done)
 
+	// tcell drops events once its small event queue is full, which happens
+	// easily when text is pasted. Read them as soon as they arrive.
+	raw := make(chan tcell.Event, 1024)
 	go func() {
+		defer close(raw)
 		for {
-			switch ev := ui.screen.PollEvent().(type) {
-			case *tcell.EventKey:
-				ui.handleKeyEvent(ev)
-			case *tcell.EventMouse:
-				ui.handleMouseEvent(ev)
-			case *tcell.EventResize:
-				ui.handleResizeEvent(ev)
+			ev := screen.PollEvent()
+			if ev == nil {
+				// The screen has been shut down.
+				return
+			}
+			select {
+			case raw <- ev:
+			case <-stop:
+				return
 			}
 		}
 	}()
 
+	var (
+		// buttons holds the mouse buttons currently held down.
+		buttons tcell.ButtonMask
+
+		paste   pasteDecoder
+		timeout <-chan time.Time
+	)
+
 	for {
+		var evs []event
+
 		select {
-		case <-ui.quit:
-			failed = false
-			return nil
-		case ev := <-ui.eventQueue:
-			ui.handleEvent(ev)
+		case tev, ok := <-raw:
+			if !ok {
+				return
+			}
+			switch tev := tev.(type) {
+			case *tcell.EventKey:
+				evs = paste.feed(convertKeyEvent(tev))
+			case *tcell.EventMouse:
+				var ev MouseEvent
+				ev, buttons = convertMouseEvent(tev, buttons)
+				evs = []event{ev}
+			case *tcell.EventResize:
+				if ui.queueResize() {
+					evs = []event{paintEvent{}}
+				}
+			case *inputClosedEvent:
+				// Nothing more will arrive, e.g. because the
+				// connection to the terminal was closed.
+				if tev.err != nil && tev.err != io.EOF {
+					err := tev.err
+					ui.Post(func() {
+						ui.fail(err)
+					})
+				} else {
+					ui.Quit()
+				}
+				return
+			}
+		case <-timeout:
+			evs = paste.flush()
+		}
+
+		timeout = nil
+		if d := paste.timeout(); d > 0 {
+			timeout = time.After(d)
+		}
+
+		for _, ev := range evs {
+			select {
+			case ui.eventQueue <- ev:
+			case <-stop:
+				return
+			}
+		}
+	}
+}
+
+// setBracketedPaste turns bracketed paste mode on or off, on screens that
+// can write to the terminal.
+func setBracketedPaste(screen terminal, enabled bool) {
+	tty, ok := screen.(interface {
+		TPuts(s string)
+	})
+	if !ok {
+		return
+	}
+	if enabled {
+		tty.TPuts(enableBracketedPaste)
+	} else {
+		tty.TPuts(disableBracketedPaste)
+	}
+}
+
+// Suspend shuts down the screen and restores the terminal, calls fn, and then
+// reinitializes the screen and repaints the UI. Use it to run programs that
+// need the terminal, like an editor or a shell. The events that arrive while
+// the UI is suspended are dropped.
+//
+// Suspend returns the error returned by fn. If the screen can't be
+// reinitialized, the UI shuts down and the error is returned by Run.
+func (ui *tcellUI) Suspend(fn func() error) error {
+	if !ui.onLoop() {
+		var err error
+		ui.Update(func() {
+			err = ui.Suspend(fn)
+		})
+		return err
+	}
+
+	ui.finiScreen()
+	ui.stopPolling()
+
+	err := fn()
+
+	if ui.newScreen != nil {
+		screen, serr := ui.newScreen()
+		if serr != nil {
+			ui.fail(serr)
+			return err
 		}
+		ui.screen = screen
+	}
+	if serr := ui.initScreen(); serr != nil {
+		ui.fail(serr)
+		return err
+	}
+
+	ui.startPolling()
+
+	// The terminal may have been resized while the UI was suspended.
+	ui.updateSize()
+
+	ui.painter.Invalidate()
+	ui.needsPaint = true
+
+	return err
+}
+
+// suspendProcess stops the process, as if Ctrl+Z was pressed in a shell, and
+// resumes the UI when the process is continued.
+func (ui *tcellUI) suspendProcess() {
+	if err := ui.Suspend(func() error {
+		return stopProcess(ui.jobSignals)
+	}); err != nil {
+		logger.Printf("Failed to suspend: %v", err)
+	}
+}
+
+// fail shuts down the UI with an error.
+func (ui *tcellUI) fail(err error) {
+	ui.err = err
+	ui.Quit()
+}
+
+// paint repaints the UI immediately.
+func (ui *tcellUI) paint() {
+	ui.painter.Repaint(ui.layers)
+	ui.needsPaint = false
+	ui.lastPaint = time.Now()
+
+	if ui.recorder != nil && ui.recorder.Err() != nil {
+		logger.Printf("Failed to record: %v", ui.recorder.Err())
+		ui.Record(nil)
+	}
+}
+
+// Record records every frame painted from now on to w, as an asciicast v2
+// recording. The first frame is painted in full. Record(nil) stops recording.
+// It must be called before Run, or from the UI goroutine.
+func (ui *tcellUI) Record(w io.Writer) {
+	if ui.recorder != nil {
+		ui.painter.surface = ui.recorder.surface
+		ui.recorder = nil
+	}
+	if w == nil {
+		return
 	}
+
+	ui.recorder = NewRecorder(w, ui.painter.surface)
+	ui.painter.surface = ui.recorder
+	ui.painter.Invalidate()
+	ui.needsPaint = true
 }
 
+// flush paints the frame held back by the frame rate limit, if any, so that
+// the terminal is left showing the final state of the widgets, e.g. by an
+// inline UI.
+func (ui *tcellUI) flush() {
+	if ui.needsPaint {
+		ui.paint()
+	}
+}
+
+// handleEvent dispatches an event. The UI is repainted with the next frame.
 func (ui *tcellUI) handleEvent(ev event) {
 	switch e := ev.(type) {
 	case KeyEvent:
 		logger.Printf("Received key event: %s", e.Name())
-
-		for _, b := range ui.keybindings {
-			if b.match(e) {
-				b.handler()
-			}
-		}
-		ui.kbFocus.OnKeyEvent(e)
-		ui.root.OnKeyEvent(e)
-		ui.painter.Repaint(ui.root)
+		ui.propagateKeyEvent(e)
+	case PasteEvent:
+		logger.Printf("Received paste event")
+		ui.propagatePasteEvent(e)
+	case MouseEvent:
+		ui.handleMouseEvent(e)
 	case callbackEvent:
 		// Gets stuck in a print loop when the logger is a widget.
 		//logger.Printf("Received callback event")
 		e.cbFn()
-		ui.painter.Repaint(ui.root)
 	case paintEvent:
 		logger.Printf("Received paint event")
-		ui.painter.Repaint(ui.root)
+		atomic.StoreInt32(&ui.resizePending, 0)
+		ui.updateSize()
+	}
+	ui.needsPaint = true
+}
+
+// handleMouseEvent delivers a mouse event to the widget under the pointer.
+// Only the widgets of the topmost layer can be reached. Clicking a widget
+// focuses it, and right-clicking a widget with a context menu opens the menu.
+func (ui *tcellUI) handleMouseEvent(ev MouseEvent) {
+	root, origin := ui.layers.inputRoot()
+
+	if l := ui.layers.top(); l != nil && ev.Action == MousePress && !ev.Pos.In(l.bounds) {
+		switch {
+		case l.opts.OnClickBelow != nil:
+			if !l.opts.OnClickBelow(ui.layers.layerAt(ev.Pos)) {
+				return
+			}
+			root, origin = ui.layers.inputRoot()
+		case l.opts.OnClickOutside != nil:
+			l.opts.OnClickOutside()
+			return
+		default:
+			return
+		}
+	}
+
+	pos := ev.Pos
+	ev.Pos = pos.Sub(origin)
+
+	if ev.Action == MousePress {
+		switch ev.Button {
+		case MouseButtonLeft:
+			ui.kbFocus.focusAt(root, ev.Pos)
+		case MouseButtonRight:
+			ws := widgetsAt(root, ev.Pos)
+			for i := len(ws) - 1; i >= 0; i-- {
+				if m := widgetContextMenu(ws[i]); m != nil {
+					m.Show(ui, LayerOptions{Placement: PlaceAt, Pos: pos})
+					return
+				}
+			}
+		}
+	}
+
+	ui.mouse.OnMouseEvent(root, ev)
+}
+
+// propagatePasteEvent sends pasted text to the first widget that accepts
+// pastes, starting with the focused widget. If none does, the text is typed
+// as key presses instead.
+func (ui *tcellUI) propagatePasteEvent(ev PasteEvent) {
+	for _, w := range ui.kbFocus.path(ui.inputRoot()) {
+		if h, ok := w.(PasteHandler); ok {
+			h.OnPasteEvent(ev)
+			return
+		}
+	}
+
+	for _, r := range ev.Text {
+		ui.propagateKeyEvent(keyForRune(r))
 	}
 }
 
-func (ui *tcellUI) handleKeyEvent(tev *tcell.EventKey) {
-	ui.eventQueue <- KeyEvent{
+// propagateKeyEvent sends a key event to the focused widget, then to the
+// containers it's in, and then to the global keybindings, until one of them
+// consumes it. Each widget's keymap gets the key right after the widget
+// itself. Keybindings always consume the keys they match.
+//
+// While a key sequence is pending, the keymap it belongs to gets the key
+// first, so that the widgets don't see the keys of a chord. A key that
+// doesn't continue the sequence cancels it, and is then handled like any
+// other key.
+//
+// Keys that aren't consumed move the focus, or suspend the process.
+func (ui *tcellUI) propagateKeyEvent(ev KeyEvent) {
+	ev.state = &eventState{}
+
+	if len(ui.pendingKeys) > 0 && ui.handleKeybindings(ui.pendingKeymap, ev) {
+		return
+	}
+
+	// Alt and an arrow key move the focus away from widgets that use the
+	// arrow keys, unless a keybinding takes them.
+	skip := ui.kbFocus.skipsWidgets(ev)
+
+	for _, w := range ui.kbFocus.path(ui.inputRoot()) {
+		if !skip {
+			w.OnKeyEvent(ev)
+		}
+		if ev.Consumed() {
+			return
+		}
+		if m := widgetKeymap(w); m != nil && ui.handleKeybindings(m, ev) {
+			return
+		}
+	}
+
+	if ui.handleKeybindings(ui.keymap, ev) {
+		return
+	}
+
+	if ev.Key == KeyCtrlZ && ui.jobControl {
+		ui.suspendProcess()
+		return
+	}
+
+	// Lay out the widgets first, so that the focus can move to widgets
+	// shown since the last frame, and skips those hidden since.
+	if size := ui.layers.Size(); !size.Eq(image.Point{}) {
+		ui.layers.Resize(size)
+	}
+	ui.kbFocus.OnKeyEvent(ui.inputRoot(), ev)
+}
+
+func convertKeyEvent(tev *tcell.EventKey) KeyEvent {
+	return KeyEvent{
 		Key:       Key(tev.Key()),
 		Rune:      tev.Rune(),
 		Modifiers: ModMask(tev.Modifiers()),
 	}
 }
 
-func (ui *tcellUI) handleMouseEvent(ev *tcell.EventMouse) {
+// convertMouseEvent translates a tcell mouse event into a MouseEvent. tcell
+// only reports which buttons are currently held down, so presses, releases
+// and drags are derived from the buttons that were held down before the
+// event. It returns the buttons held down after the event.
+func convertMouseEvent(ev *tcell.EventMouse, prev tcell.ButtonMask) (MouseEvent, tcell.ButtonMask) {
 	x, y := ev.Position()
-	ui.eventQueue <- MouseEvent{Pos: image.Pt(x, y)}
+
+	me := MouseEvent{
+		Pos:       image.Pt(x, y),
+		Modifiers: ModMask(ev.Modifiers()),
+	}
+
+	btns := ev.Buttons()
+
+	switch {
+	case btns&tcell.WheelUp != 0:
+		me.Action, me.Wheel = MouseWheel, WheelUp
+		return me, prev
+	case btns&tcell.WheelDown != 0:
+		me.Action, me.Wheel = MouseWheel, WheelDown
+		return me, prev
+	case btns&tcell.WheelLeft != 0:
+		me.Action, me.Wheel = MouseWheel, WheelLeft
+		return me, prev
+	case btns&tcell.WheelRight != 0:
+		me.Action, me.Wheel = MouseWheel, WheelRight
+		return me, prev
+	}
+
+	held := btns & (tcell.Button1 | tcell.Button2 | tcell.Button3)
+
+	switch {
+	case held == 0 && prev == 0:
+		me.Action = MouseMove
+	case held == 0:
+		me.Action = MouseRelease
+		me.Button = convertButton(prev)
+	case held&^prev != 0:
+		me.Action = MousePress
+		me.Button = convertButton(held &^ prev)
+	case held != prev:
+		me.Action = MouseRelease
+		me.Button = convertButton(prev &^ held)
+	default:
+		me.Action = MouseDrag
+		me.Button = convertButton(held)
+	}
+
+	return me, held
+}
+
+func convertButton(btns tcell.ButtonMask) MouseButton {
+	switch {
+	case btns&tcell.Button1 != 0:
+		return MouseButtonLeft
+	case btns&tcell.Button2 != 0:
+		return MouseButtonMiddle
+	case btns&tcell.Button3 != 0:
+		return MouseButtonRight
+	}
+	return MouseButtonNone
+}
+
+// OnResize sets a function to be called with the new size of the screen
+// whenever the terminal is resized. It's called before the widgets are laid
+// out for the new size, so it may change them, e.g. to switch to a more
+// compact layout.
+func (ui *tcellUI) OnResize(fn func(size image.Point)) {
+	ui.onResize = fn
+}
+
+// Size returns the size of the screen, in cells. It's zero until the UI is
+// running.
+func (ui *tcellUI) Size() image.Point {
+	return ui.size
+}
+
+// updateSize reads the size of the screen, and calls the resize callback if
+// it has changed.
+func (ui *tcellUI) updateSize() {
+	size := ui.painter.surface.Size()
+	if size == ui.size {
+		return
+	}
+	ui.size = size
+	if ui.onResize != nil {
+		ui.onResize(size)
+	}
+}
+
+// queueResize returns whether a resize needs to be sent to the event loop.
+// It returns false while a previous resize is waiting to be handled.
+func (ui *tcellUI) queueResize() bool {
+	return atomic.CompareAndSwapInt32(&ui.resizePending, 0, 1)
+}
+
+// AfterFunc waits for the duration to elapse and then calls fn in the UI
+// goroutine. The timer is stopped when the UI shuts down.
+func (ui *tcellUI) AfterFunc(d time.Duration, fn func()) *Timer {
+	t := newTimer()
+	go runTimer(t, d, false, fn, ui.eventQueue, ui.done)
+	return t
+}
+
+// Every calls fn in the UI goroutine each time the duration elapses, until the
+// timer is stopped or the UI shuts down.
+func (ui *tcellUI) Every(d time.Duration, fn func()) *Timer {
+	t := newTimer()
+	go runTimer(t, d, true, fn, ui.eventQueue, ui.done)
+	return t
 }
 
-func (ui *tcellUI) handleResizeEvent(ev *tcell.EventResize) {
-	ui.eventQueue <- paintEvent{}
+// RequestFrame schedules fn to be called in the UI goroutine before the next
+// frame is drawn. All functions requested for the same frame are called with
+// the same time, followed by a single repaint.
+func (ui *tcellUI) RequestFrame(fn func(t time.Time)) {
+	ui.frameMu.Lock()
+	defer ui.frameMu.Unlock()
+
+	if len(ui.frameCallbacks) == 0 {
+		d := ui.frameInterval
+		if d <= 0 {
+			d = time.Second / DefaultMaxFrameRate
+		}
+		ui.AfterFunc(d, ui.drawFrame)
+	}
+	ui.frameCallbacks = append(ui.frameCallbacks, fn)
 }
 
-// Quit signals to the UI to start shutting down.
+func (ui *tcellUI) drawFrame() {
+	ui.frameMu.Lock()
+	fns := ui.frameCallbacks
+	ui.frameCallbacks = nil
+	ui.frameMu.Unlock()
+
+	now := time.Now()
+	for _, fn := range fns {
+		fn(now)
+	}
+}
+
+// Quit signals to the UI to start shutting down. The screen is restored
+// before Run returns. Calling Quit more than once has no effect.
 func (ui *tcellUI) Quit() {
 	logger.Printf("Quitting")
-	ui.screen.Fini()
-	ui.quit <- struct{}{}
+
+	select {
+	case ui.quit <- struct{}{}:
+	default:
+		// The UI is already shutting down.
+	}
+}
+
+// OnQuit registers a function to be called in the UI goroutine when the UI
+// shuts down. Hooks are called in the order they were registered, after the
+// terminal has been restored.
+func (ui *tcellUI) OnQuit(fn func()) {
+	ui.quitHooks = append(ui.quitHooks, fn)
+}
+
+func (ui *tcellUI) runQuitHooks() {
+	for _, fn := range ui.quitHooks {
+		fn()
+	}
 }
 
 // Schedule an update of the UI, running the given
@@ -189,14 +1092,91 @@ func (ui *tcellUI) Quit() {
 // `go run -race` or `go install -race` to detect this!)
 //
 // Calling Update from within an event handler, or from within an Update call,
-// is an error, and will deadlock.
+// runs fn immediately, since waiting for the UI goroutine would deadlock.
 func (ui *tcellUI) Update(fn func()) {
+	if ui.onLoop() {
+		fn()
+		return
+	}
+
 	blk := make(chan struct{})
-	ui.eventQueue <- callbackEvent{func() {
+	ev := callbackEvent{func() {
 		fn()
 		close(blk)
 	}}
-	<-blk
+
+	// Don't wait forever if the UI shuts down, e.g. because fn panicked.
+	select {
+	case ui.eventQueue <- ev:
+	case <-ui.done:
+		return
+	}
+	select {
+	case <-blk:
+	case <-ui.done:
+	}
+}
+
+// Post schedules fn to be called in the UI goroutine and returns immediately.
+// Functions are called in the order they were posted, followed by a repaint.
+//
+// Unlike Update, Post is safe to call from anywhere, including event handlers
+// and other callbacks running in the UI goroutine.
+func (ui *tcellUI) Post(fn func()) {
+	ui.postMu.Lock()
+	ui.posted = append(ui.posted, fn)
+	ui.postMu.Unlock()
+
+	select {
+	case ui.postSignal <- struct{}{}:
+	default:
+		// The loop has already been notified.
+	}
+}
+
+// runPosted calls the functions queued by Post. Functions posted while doing
+// so are left for the next iteration of the event loop.
+func (ui *tcellUI) runPosted() {
+	ui.postMu.Lock()
+	fns := ui.posted
+	ui.posted = nil
+	ui.postMu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// runOnLoop calls fn. The event loop runs within it, so that onLoop can find
+// it on the stack of the loop goroutine.
+//
+//go:noinline
+func runOnLoop(fn func()) {
+	fn()
+}
+
+var runOnLoopEntry = runtime.FuncForPC(reflect.ValueOf(runOnLoop).Pointer()).Entry()
+
+// onLoop returns whether it's called from the goroutine running the event
+// loop. The looping flag alone can't tell, since other goroutines may call
+// Update while the loop runs a callback, so the stack of the caller is
+// checked for runOnLoop as well.
+func (ui *tcellUI) onLoop() bool {
+	if atomic.LoadInt32(&ui.looping) == 0 {
+		return false
+	}
+	pcs := make([]uintptr, 32)
+	for skip := 2; ; skip += len(pcs) {
+		n := runtime.Callers(skip, pcs)
+		for _, pc := range pcs[:n] {
+			if f := runtime.FuncForPC(pc - 1); f != nil && f.Entry() == runOnLoopEntry {
+				return true
+			}
+		}
+		if n < len(pcs) {
+			return false
+		}
+	}
 }
 
 var _ Surface = &tcellSurface{}
@@ -211,7 +1191,8 @@ func (s *tcellSurface) SetCell(x, y int, ch rune, style Style) {
 		Background(convertColor(style.Bg, false)).
 		Reverse(style.Reverse == DecorationOn).
 		Bold(style.Bold == DecorationOn).
-		Underline(style.Underline == DecorationOn)
+		Underline(style.Underline == DecorationOn).
+		Dim(style.Dim == DecorationOn)
 
 	s.screen.SetContent(x, y, ch, nil, st)
 }
@@ -0,0 +1,274 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+func TestConvertMouseEvent(t *testing.T) {
+	for _, tt := range []struct {
+		test   string
+		prev   tcell.ButtonMask
+		btns   tcell.ButtonMask
+		want   MouseEvent
+		remain tcell.ButtonMask
+	}{
+		{"move", 0, 0, MouseEvent{Action: MouseMove}, 0},
+		{"press", 0, tcell.Button1, MouseEvent{Action: MousePress, Button: MouseButtonLeft}, tcell.Button1},
+		{"drag", tcell.Button1, tcell.Button1, MouseEvent{Action: MouseDrag, Button: MouseButtonLeft}, tcell.Button1},
+		{"release", tcell.Button3, 0, MouseEvent{Action: MouseRelease, Button: MouseButtonRight}, 0},
+		{"press second button", tcell.Button1, tcell.Button1 | tcell.Button2, MouseEvent{Action: MousePress, Button: MouseButtonMiddle}, tcell.Button1 | tcell.Button2},
+		{"release one of two buttons", tcell.Button1 | tcell.Button2, tcell.Button2, MouseEvent{Action: MouseRelease, Button: MouseButtonLeft}, tcell.Button2},
+		{"wheel", tcell.Button1, tcell.WheelDown, MouseEvent{Action: MouseWheel, Wheel: WheelDown}, tcell.Button1},
+	} {
+		tt := tt
+		t.Run(tt.test, func(t *testing.T) {
+			ev := tcell.NewEventMouse(3, 4, tt.btns, tcell.ModCtrl)
+
+			got, remain := convertMouseEvent(ev, tt.prev)
+
+			tt.want.Pos = image.Pt(3, 4)
+			tt.want.Modifiers = ModCtrl
+			if got != tt.want {
+				t.Errorf("got = %+v; want = %+v", got, tt.want)
+			}
+			if remain != tt.remain {
+				t.Errorf("got = %v; want = %v", remain, tt.remain)
+			}
+		})
+	}
+}
+
+func TestUI_CoalesceRepaints(t *testing.T) {
+	w := &drawCounter{text: "a"}
+
+	ui := NewTestUI(w, 5, 1)
+	ui.SetMaxFrameRate(1)
+	defer runTestUI(t, ui)()
+
+	for i := 0; i < 50; i++ {
+		ui.Update(func() {})
+		ui.Post(func() {})
+	}
+	ui.WaitIdle()
+
+	// Everything happened within the same frame as the initial paint, so the
+	// repaint is held back until the next frame.
+	ui.Update(func() {
+		if w.draws != 1 {
+			t.Errorf("got = %d; want = %d", w.draws, 1)
+		}
+	})
+
+	ui.Snapshot()
+
+	ui.Update(func() {
+		if w.draws != 2 {
+			t.Errorf("got = %d; want = %d", w.draws, 2)
+		}
+	})
+}
+
+func TestUI_CollapseResizeEvents(t *testing.T) {
+	ui := NewTestUI(NewLabel(""), 5, 1)
+
+	go ui.sendResize()
+	ev := <-ui.eventQueue
+
+	// The first resize hasn't been handled yet, so these are dropped rather
+	// than blocking.
+	for i := 0; i < 5; i++ {
+		ui.sendResize()
+	}
+
+	ui.handleEvent(ev)
+
+	go ui.sendResize()
+	if _, ok := (<-ui.eventQueue).(paintEvent); !ok {
+		t.Errorf("expected paint event")
+	}
+}
+
+func TestUI_OnResize(t *testing.T) {
+	full := NewHBox(NewLabel("left"), NewLabel("right"))
+	compact := NewLabel("compact")
+
+	ui := NewTestUI(full, 10, 1)
+
+	var sizes []image.Point
+	ui.OnResize(func(size image.Point) {
+		sizes = append(sizes, size)
+		if size.X < 10 {
+			ui.SetWidget(compact)
+		} else {
+			ui.SetWidget(full)
+		}
+	})
+	defer runTestUI(t, ui)()
+
+	ui.Update(func() {
+		if got := ui.Size(); got != image.Pt(10, 1) {
+			t.Errorf("got = %v; want = %v", got, image.Pt(10, 1))
+		}
+	})
+
+	ui.Resize(8, 1)
+	ui.Resize(8, 1)
+
+	want := `
+compact.
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+
+	ui.Resize(12, 1)
+
+	want = `
+left  right 
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+
+	ui.Update(func() {
+		want := []image.Point{{8, 1}, {12, 1}}
+		if len(sizes) != len(want) || sizes[0] != want[0] || sizes[1] != want[1] {
+			t.Errorf("got = %v; want = %v", sizes, want)
+		}
+		if got := ui.Size(); got != image.Pt(12, 1) {
+			t.Errorf("got = %v; want = %v", got, image.Pt(12, 1))
+		}
+	})
+}
+
+type panicWidget struct {
+	WidgetBase
+}
+
+func (w *panicWidget) OnKeyEvent(ev KeyEvent) {
+	panic("boom")
+}
+
+func TestUI_RecoverPanic(t *testing.T) {
+	ui := NewTestUI(&panicWidget{}, 5, 1)
+
+	var quit bool
+	ui.OnQuit(func() {
+		quit = true
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ui.Run()
+	}()
+
+	ui.SendKey(KeyEvent{Key: KeyRune, Rune: 'a'})
+
+	err := <-done
+
+	perr, ok := err.(*PanicError)
+	if !ok {
+		t.Fatalf("got = %v; want = *PanicError", err)
+	}
+	if perr.Value != "boom" {
+		t.Errorf("got = %v; want = %v", perr.Value, "boom")
+	}
+	if !strings.Contains(string(perr.Stack), "OnKeyEvent") {
+		t.Errorf("expected stack trace to contain the panicking function:\n%s", perr.Stack)
+	}
+	if !quit {
+		t.Errorf("expected quit hooks to be called")
+	}
+
+	// Updates don't block once the UI has shut down.
+	ui.Update(func() {})
+}
+
+func TestUI_RunContext(t *testing.T) {
+	ui := NewTestUI(NewLabel(""), 5, 1)
+
+	var hooks []string
+	ui.OnQuit(func() { hooks = append(hooks, "a") })
+	ui.OnQuit(func() { hooks = append(hooks, "b") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ui.RunContext(ctx)
+	}()
+
+	ui.WaitIdle()
+	cancel()
+
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got = %v; want = %v", err, context.Canceled)
+	}
+	if strings.Join(hooks, "") != "ab" {
+		t.Errorf("got = %v; want = %v", hooks, []string{"a", "b"})
+	}
+
+	// Quitting a UI that has already shut down has no effect.
+	ui.Quit()
+	ui.Quit()
+
+	// Neither has running it again.
+	if err := ui.Run(); err != errAlreadyRun {
+		t.Errorf("got = %v; want = %v", err, errAlreadyRun)
+	}
+	if strings.Join(hooks, "") != "ab" {
+		t.Errorf("got = %v; want = %v", hooks, []string{"a", "b"})
+	}
+}
+
+func TestUI_Suspend(t *testing.T) {
+	w := newDrawCounter("a")
+
+	ui := NewTestUI(w, 5, 1)
+
+	var calls int
+	suspended := errors.New("suspended")
+	ui.SetKeybinding("Ctrl+E", func() {
+		if err := ui.Suspend(func() error {
+			calls++
+			return nil
+		}); err != nil {
+			t.Error(err)
+		}
+	})
+	defer runTestUI(t, ui)()
+
+	if err := ui.Suspend(func() error {
+		calls++
+		return suspended
+	}); err != suspended {
+		t.Errorf("got = %v; want = %v", err, suspended)
+	}
+
+	if err := ui.Type("<Ctrl+E>"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+a....
+`
+	if diff := surfaceEquals(ui.Snapshot(), want); diff != "" {
+		t.Error(diff)
+	}
+
+	// The UI is fully repainted after resuming.
+	ui.Update(func() {
+		if calls != 2 {
+			t.Errorf("got = %d; want = %d", calls, 2)
+		}
+		if w.draws != 2 {
+			t.Errorf("got = %d; want = %d", w.draws, 2)
+		}
+	})
+}
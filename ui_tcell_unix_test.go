@@ -0,0 +1,83 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package tui
+
+import (
+	"image"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestUI_Signal(t *testing.T) {
+	for _, sig := range []syscall.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP} {
+		t.Run(sig.String(), func(t *testing.T) {
+			ui := NewTestUI(NewLabel(""), 5, 1)
+			ui.handleSignals = true
+
+			var quit bool
+			ui.OnQuit(func() {
+				quit = true
+			})
+
+			done := make(chan error, 1)
+			go func() {
+				done <- ui.Run()
+			}()
+
+			ui.WaitIdle()
+			syscall.Kill(syscall.Getpid(), sig)
+
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Error(err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("UI did not shut down")
+			}
+			if !quit {
+				t.Errorf("expected quit hooks to be called")
+			}
+		})
+	}
+}
+
+func TestStreamUI_IgnoresSignals(t *testing.T) {
+	// Catch the signal here, so that it doesn't end the test.
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	defer signal.Stop(sig)
+
+	r, w := io.Pipe()
+	defer w.Close()
+
+	ui, err := NewStreamUI(NewLabel(""), r, ioutil.Discard, StreamOptions{Size: image.Pt(5, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- ui.Run()
+	}()
+	defer func() {
+		ui.Quit()
+		<-done
+	}()
+
+	// A session of a server isn't ended by the signals sent to the server.
+	ui.Update(func() {})
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	<-sig
+
+	select {
+	case <-done:
+		t.Fatal("UI shut down")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -29,6 +29,41 @@ type Widget interface {
 	IsFocused() bool
 }
 
+// Container is implemented by widgets that contain other widgets. It lets the
+// UI find its way through the widget tree, e.g. to deliver mouse events.
+type Container interface {
+	Widget
+
+	// Children returns the contained widgets in the order they are laid out.
+	Children() []Widget
+
+	// ChildBounds returns the area occupied by a child widget, in the local
+	// coordinates of the container.
+	ChildBounds(w Widget) image.Rectangle
+}
+
+// DirtyTracker is implemented by widgets that know whether they need to be
+// redrawn. The painter skips drawing widgets that aren't dirty, and reuses what
+// they painted in the previous frame instead.
+type DirtyTracker interface {
+	// IsDirty returns whether the widget has changed since it was last
+	// drawn.
+	IsDirty() bool
+	// MarkClean is called by the painter once the widget has been drawn.
+	MarkClean()
+}
+
+// Focusable is implemented by widgets that can be focused. TreeFocusChain
+// moves the focus between the focusable widgets of a widget tree.
+type Focusable interface {
+	Widget
+
+	// Focusable returns whether the widget can be focused right now, e.g.
+	// false while it's disabled. The widgets it contains are skipped by
+	// TreeFocusChain as well.
+	Focusable() bool
+}
+
 // WidgetBase defines base attributes and operations for all widgets.
 type WidgetBase struct {
 	size image.Point
@@ -36,16 +71,80 @@ type WidgetBase struct {
 	sizePolicyX SizePolicy
 	sizePolicyY SizePolicy
 
-	focused bool
+	focused  bool
+	disabled bool
+
+	onFocusChanged func(focused bool)
+	onFocusIn      func()
+	onFocusOut     func()
+
+	trackDirty bool
+	clean      bool
+
+	// owner is the built-in widget that turned on dirty tracking, if any.
+	owner Widget
+
+	keymap      *Keymap
+	contextMenu *Menu
 }
 
 // Draw is an empty operation to fulfill the Widget interface.
 func (w *WidgetBase) Draw(p *Painter) {
 }
 
-// SetFocused focuses the widget.
+// SetFocused focuses the widget. The focus hooks are called if the focus
+// changed.
 func (w *WidgetBase) SetFocused(f bool) {
+	if w.focused == f {
+		return
+	}
+	w.MarkDirty()
 	w.focused = f
+
+	if w.onFocusChanged != nil {
+		w.onFocusChanged(f)
+	}
+	if f && w.onFocusIn != nil {
+		w.onFocusIn()
+	}
+	if !f && w.onFocusOut != nil {
+		w.onFocusOut()
+	}
+}
+
+// SetEnabled sets whether the widget is enabled. Disabled widgets lose their
+// focus, can't be focused, and ignore the mouse. The UI moves its focus away
+// from a disabled widget before the next frame, and calls OnFocusChanged.
+func (w *WidgetBase) SetEnabled(enabled bool) {
+	if w.disabled == enabled {
+		w.MarkDirty()
+	}
+	w.disabled = !enabled
+	if !enabled {
+		w.SetFocused(false)
+	}
+}
+
+// IsEnabled returns whether the widget is enabled.
+func (w *WidgetBase) IsEnabled() bool {
+	return !w.disabled
+}
+
+// OnFocusChanged sets a function to be called whenever the widget gains or
+// loses focus.
+func (w *WidgetBase) OnFocusChanged(fn func(focused bool)) {
+	w.onFocusChanged = fn
+}
+
+// OnFocusIn sets a function to be called whenever the widget gains focus.
+func (w *WidgetBase) OnFocusIn(fn func()) {
+	w.onFocusIn = fn
+}
+
+// OnFocusOut sets a function to be called whenever the widget loses focus,
+// e.g. to validate the text of an Entry when the user leaves it.
+func (w *WidgetBase) OnFocusOut(fn func()) {
+	w.onFocusOut = fn
 }
 
 // IsFocused returns whether the widget is focused.
@@ -81,9 +180,77 @@ func (w *WidgetBase) SizePolicy() (SizePolicy, SizePolicy) {
 
 // Resize sets the size of the widget.
 func (w *WidgetBase) Resize(size image.Point) {
+	if w.size != size {
+		w.MarkDirty()
+	}
 	w.size = size
 }
 
+// SetDirtyTracking sets whether the widget tracks its dirty state. A widget
+// that tracks its dirty state must call MarkDirty whenever its appearance
+// changes. Widgets that don't are redrawn in every frame.
+//
+// The built-in widgets track their dirty state, but a type that embeds one of
+// them is redrawn in every frame, unless it calls SetDirtyTracking itself.
+func (w *WidgetBase) SetDirtyTracking(enabled bool) {
+	w.trackDirty = enabled
+	w.clean = false
+	w.owner = nil
+}
+
+// trackDirtyFor turns on dirty tracking for a built-in widget. The dirty state
+// is then only relied on while drawing owner itself, and not a type that
+// embeds it, which may draw more than owner marks dirty.
+func (w *WidgetBase) trackDirtyFor(owner Widget) {
+	w.SetDirtyTracking(true)
+	w.owner = owner
+}
+
+// dirtyOwner returns the widget passed to trackDirtyFor, or nil if the dirty
+// state applies to any widget embedding w.
+func (w *WidgetBase) dirtyOwner() Widget {
+	return w.owner
+}
+
+// MarkDirty marks the widget as changed, so that it's redrawn in the next
+// frame.
+func (w *WidgetBase) MarkDirty() {
+	w.clean = false
+}
+
+// IsDirty returns whether the widget needs to be redrawn. Widgets that don't
+// track their dirty state always need to be redrawn.
+func (w *WidgetBase) IsDirty() bool {
+	return !w.trackDirty || !w.clean
+}
+
+// MarkClean is called by the painter once the widget has been drawn.
+func (w *WidgetBase) MarkClean() {
+	w.clean = true
+}
+
+// SetKeymap sets keybindings that apply while the widget, or any widget
+// inside it, has focus.
+func (w *WidgetBase) SetKeymap(m *Keymap) {
+	w.keymap = m
+}
+
+// Keymap returns the keymap of the widget, or nil if it has none.
+func (w *WidgetBase) Keymap() *Keymap {
+	return w.keymap
+}
+
+// SetContextMenu sets a menu to be shown when the widget is clicked with the
+// right mouse button.
+func (w *WidgetBase) SetContextMenu(m *Menu) {
+	w.contextMenu = m
+}
+
+// ContextMenu returns the context menu of the widget, or nil if it has none.
+func (w *WidgetBase) ContextMenu() *Menu {
+	return w.contextMenu
+}
+
 // OnKeyEvent is an empty operation to fulfill the Widget interface.
 func (w *WidgetBase) OnKeyEvent(ev KeyEvent) {
 }